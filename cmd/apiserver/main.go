@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/apiserver"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/config"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 var (
@@ -40,7 +44,17 @@ func main() {
 		log.Fatalf("error occured while loading env config file: %s", err.Error())
 	}
 
-	if err := apiserver.Start(cfg); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(ctx, cfg, flag.Args()[1:]); err != nil {
+			log.Fatalf("error occured while migrating the database: %s", err.Error())
+		}
+		return
+	}
+
+	if err := apiserver.Start(ctx, cfg); err != nil {
 		log.Fatalf("error occured while starting API server: %s", err.Error())
 	}
 }
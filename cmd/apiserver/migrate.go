@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/apiserver"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/config"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/migrate"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = "usage: apiserver [flags] migrate up|down|status|goto VERSION"
+
+var errMigrateUsage = errors.New(migrateUsage)
+
+// runMigrate runs the migrate subcommand with the arguments following it.
+func runMigrate(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	db, err := apiserver.OpenDB(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := apiserver.NewMigrator(cfg.DatabaseURL, db)
+	if err != nil {
+		return err
+	}
+
+	var done []*migrate.Migration
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		done, err = migrator.Up(ctx)
+	case args[0] == "down" && len(args) == 1:
+		done, err = migrator.Down(ctx)
+	case args[0] == "goto" && len(args) == 2:
+		version, parseErr := strconv.ParseInt(args[1], 10, 64)
+		if parseErr != nil {
+			return errMigrateUsage
+		}
+		done, err = migrator.Goto(ctx, version)
+	case args[0] == "status" && len(args) == 1:
+		return printMigrationStatus(ctx, migrator)
+	default:
+		return errMigrateUsage
+	}
+
+	for _, migration := range done {
+		fmt.Printf("%d_%s\n", migration.Version, migration.Name)
+	}
+
+	if err == nil && len(done) == 0 {
+		fmt.Println("no change")
+	}
+
+	return err
+}
+
+func printMigrationStatus(ctx context.Context, migrator *migrate.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+
+	return w.Flush()
+}
@@ -21,9 +21,35 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/cache/stats": {
+            "get": {
+                "description": "get the number of the exchange rate lookups served from the cache and the ones that went to the database",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "other"
+                ],
+                "summary": "Rate cache statistics",
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/cachestore.Stats"
+                        }
+                    },
+                    "404": {
+                        "description": "The cache is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
         "/convert": {
             "get": {
-                "description": "convert the value from one currency to another according to the exchange rate",
+                "description": "convert the value from one currency to another according to the exchange rate, the rate of the reverse pair is inverted if the direct one isn't registered, if neither is, the conversion goes through a chain of the registered pairs",
                 "consumes": [
                     "application/json"
                 ],
@@ -55,6 +81,46 @@ const docTemplate = `{
                         "name": "value",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Convert at the rate that was in effect at this RFC 3339 timestamp",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Convert at the rate that was in effect at the end of this YYYY-MM-DD date (UTC)",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "half_even",
+                            "half_up",
+                            "down",
+                            "up"
+                        ],
+                        "type": "string",
+                        "description": "The mode of rounding the result to the minor unit of the target currency, half_even by default",
+                        "name": "rounding",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The max age of the rates in seconds, overrides the configured one, 0 means no limit",
+                        "name": "max_age",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "flag",
+                            "reject"
+                        ],
+                        "type": "string",
+                        "description": "Whether to flag the conversion at a rate older than the max age as stale or reject it, the configured policy by default",
+                        "name": "on_stale",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -67,33 +133,541 @@ const docTemplate = `{
                     "400": {
                         "description": "Missing parameters",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.errorResponse"
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "There is no record of the exchange rate",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid parameters or unsupported currencies",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
                         }
                     },
-                    "404": {
-                        "description": "There is no record of the exchange rate",
+                    "503": {
+                        "description": "The exchange rate is stale",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/convert/batch": {
+            "post": {
+                "description": "convert the values of the items like GET /convert does, the rate of every distinct pair is found once.\nThe items are converted independently, each of them gets either a result or an error, in the order they were given,\na malformed item included.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "other"
+                ],
+                "summary": "Batch currency conversion",
+                "parameters": [
+                    {
+                        "description": "The items to convert",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/apiserver.convertBatchItem"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Convert at the rates that were in effect at this RFC 3339 timestamp",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Convert at the rates that were in effect at the end of this YYYY-MM-DD date (UTC)",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "half_even",
+                            "half_up",
+                            "down",
+                            "up"
+                        ],
+                        "type": "string",
+                        "description": "The mode of rounding the results to the minor unit of the target currencies, half_even by default",
+                        "name": "rounding",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The max age of the rates in seconds, overrides the configured one, 0 means no limit",
+                        "name": "max_age",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "flag",
+                            "reject"
+                        ],
+                        "type": "string",
+                        "description": "Whether to flag the conversions at rates older than the max age as stale or reject them, the configured policy by default",
+                        "name": "on_stale",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.convertBatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "The payload isn't an array",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid parameters or too many items",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/providers": {
+            "get": {
+                "description": "get the state of the circuit breakers of the upstream exchange rate providers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "provider"
+                ],
+                "summary": "Rate providers status",
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/provider.Status"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rate": {
+            "post": {
+                "description": "create a record of the exchange rate between two currencies",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rate"
+                ],
+                "summary": "Create an exchange rate",
+                "parameters": [
+                    {
+                        "description": "An exchange rate information",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.createRateQuery"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/model.Rate"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing parameters or invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "409": {
+                        "description": "An exchange rate record with these currencies already exists",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid parameters or unsupported currencies",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "503": {
+                        "description": "The exchange rate providers are unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rate/{from}/{to}/history": {
+            "get": {
+                "description": "get the values the exchange rate had over a period of time, optionally downsampled to the last value of every hour or day",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rate"
+                ],
+                "summary": "Exchange rate history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The first currency of the exchange rate",
+                        "name": "from",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The second currency of the exchange rate",
+                        "name": "to",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The start of the period in RFC 3339 format, 24 hours before the end by default",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The end of the period in RFC 3339 format, the current time by default",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "raw",
+                            "hour",
+                            "day"
+                        ],
+                        "type": "string",
+                        "description": "The downsampling interval",
+                        "name": "interval",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.rateHistoryResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid parameters or unsupported currencies",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rate/{id}": {
+            "get": {
+                "description": "get the exchange rate record by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rate"
+                ],
+                "summary": "Get an exchange rate",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The ID of the exchange rate record",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/model.Rate"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "There is no record of the exchange rate",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "override the value of the exchange rate record, the record gets the \"manual\" source.\nIf pinned_until is set, the rate isn't updated automatically until then, author and reason are required in this case.\nEvery change is recorded in the audit trail of the rate.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rate"
+                ],
+                "summary": "Set an exchange rate manually",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The ID of the exchange rate record",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "A new value of the exchange rate",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.updateRateQuery"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/model.Rate"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, missing parameters or invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "There is no record of the exchange rate",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "stop tracking the exchange rate, its history is kept",
+                "tags": [
+                    "rate"
+                ],
+                "summary": "Delete an exchange rate",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The ID of the exchange rate record",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "There is no record of the exchange rate",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rate/{id}/audit": {
+            "get": {
+                "description": "get the manual changes of the exchange rate: who made them, when and why",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rate"
+                ],
+                "summary": "Exchange rate audit trail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The ID of the exchange rate record",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.RateAuditEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates": {
+            "get": {
+                "description": "get a page of the exchange rate records, optionally filtered by currencies",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rate"
+                ],
+                "summary": "List exchange rates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The currency that is either the first or the second one of the exchange rate",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The first currency of the exchange rate",
+                        "name": "first_currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The second currency of the exchange rate",
+                        "name": "second_currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The max number of records on the page, 50 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.errorResponse"
+                            "$ref": "#/definitions/apiserver.listRatesResponse"
                         }
                     },
                     "422": {
                         "description": "Invalid parameters",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.errorResponse"
+                            "$ref": "#/definitions/apiserver.problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.errorResponse"
+                            "$ref": "#/definitions/apiserver.problem"
                         }
                     }
                 }
-            }
-        },
-        "/rate": {
+            },
             "post": {
-                "description": "create a record of the exchange rate between two currencies",
+                "description": "create the records of the exchange rates between the base currency and each of the quote currencies\nthat isn't registered yet, all of them are taken from one response of the providers.\nThe base currency itself is skipped if it's among the quote ones.",
                 "consumes": [
                     "application/json"
                 ],
@@ -103,47 +677,53 @@ const docTemplate = `{
                 "tags": [
                     "rate"
                 ],
-                "summary": "Create an exchange rate",
+                "summary": "Create exchange rates of a base currency",
                 "parameters": [
                     {
-                        "description": "An exchange rate information",
+                        "description": "The base currency and the quote ones",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/apiserver.createRateQuery"
+                            "$ref": "#/definitions/apiserver.createRatesQuery"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Ok",
+                    "200": {
+                        "description": "Nothing was created",
                         "schema": {
-                            "$ref": "#/definitions/model.Rate"
+                            "$ref": "#/definitions/apiserver.createRatesResponse"
                         }
                     },
-                    "400": {
-                        "description": "Missing parameters or invalid payload",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.errorResponse"
+                            "$ref": "#/definitions/apiserver.createRatesResponse"
                         }
                     },
-                    "409": {
-                        "description": "An exchange rate record with these currencies already exists",
+                    "400": {
+                        "description": "Missing parameters or invalid payload",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.errorResponse"
+                            "$ref": "#/definitions/apiserver.problem"
                         }
                     },
                     "422": {
-                        "description": "Invalid parameters",
+                        "description": "Invalid parameters or unsupported base currency",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.errorResponse"
+                            "$ref": "#/definitions/apiserver.problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.errorResponse"
+                            "$ref": "#/definitions/apiserver.problem"
+                        }
+                    },
+                    "503": {
+                        "description": "The exchange rate providers are unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.problem"
                         }
                     }
                 }
@@ -151,9 +731,91 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "apiserver.conversionLeg": {
+            "type": "object",
+            "properties": {
+                "currency_from": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "currency_to": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "inverse": {
+                    "description": "the rate of the reverse pair was inverted",
+                    "type": "boolean",
+                    "example": true
+                },
+                "last_update_time": {
+                    "type": "string",
+                    "example": "2019-11-09T21:21:46+00:00"
+                },
+                "rate": {
+                    "type": "string",
+                    "example": "0.0132"
+                }
+            }
+        },
+        "apiserver.convertBatchItem": {
+            "type": "object",
+            "properties": {
+                "currency_from": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "currency_to": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "invoice-42-line-1"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "123.321"
+                }
+            }
+        },
+        "apiserver.convertBatchResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "description": "number of the items that weren't converted",
+                    "type": "integer",
+                    "example": 0
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.convertBatchResult"
+                    }
+                }
+            }
+        },
+        "apiserver.convertBatchResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/apiserver.problem"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "invoice-42-line-1"
+                },
+                "result": {
+                    "$ref": "#/definitions/apiserver.convertCurrencyResponse"
+                }
+            }
+        },
         "apiserver.convertCurrencyQuery": {
             "type": "object",
             "properties": {
+                "at": {
+                    "type": "string",
+                    "example": "2019-11-09T21:21:46+00:00"
+                },
                 "currency_from": {
                     "type": "string",
                     "example": "RUB"
@@ -162,6 +824,18 @@ const docTemplate = `{
                     "type": "string",
                     "example": "RUB"
                 },
+                "max_age": {
+                    "type": "integer",
+                    "example": 3600
+                },
+                "on_stale": {
+                    "type": "string",
+                    "example": "flag"
+                },
+                "rounding": {
+                    "type": "string",
+                    "example": "half_even"
+                },
                 "value": {
                     "type": "string",
                     "example": "123.321"
@@ -175,12 +849,48 @@ const docTemplate = `{
                     "type": "string",
                     "example": "123.321"
                 },
+                "inverse": {
+                    "description": "the rate of the reverse pair was inverted for at least one leg",
+                    "type": "boolean",
+                    "example": false
+                },
                 "last_update_time": {
+                    "description": "the oldest one of the rates used for the conversion",
                     "type": "string",
                     "example": "2019-11-09T21:21:46+00:00"
                 },
+                "legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.conversionLeg"
+                    }
+                },
+                "path": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "RUB",
+                        "USD"
+                    ]
+                },
                 "query": {
                     "$ref": "#/definitions/apiserver.convertCurrencyQuery"
+                },
+                "rate": {
+                    "type": "string",
+                    "example": "0.0132"
+                },
+                "rounded_result": {
+                    "description": "to the minor unit of the target currency",
+                    "type": "string",
+                    "example": "123.32"
+                },
+                "stale": {
+                    "description": "a rate used for the conversion is older than the max age",
+                    "type": "boolean",
+                    "example": false
                 }
             }
         },
@@ -197,11 +907,192 @@ const docTemplate = `{
                 }
             }
         },
-        "apiserver.errorResponse": {
+        "apiserver.createRatesQuery": {
+            "type": "object",
+            "properties": {
+                "all": {
+                    "description": "the quote currencies are all the supported ones",
+                    "type": "boolean",
+                    "example": false
+                },
+                "base_currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "quote_currencies": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "RUB",
+                        "EUR"
+                    ]
+                }
+            }
+        },
+        "apiserver.createRatesResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Rate"
+                    }
+                },
+                "existing": {
+                    "description": "quote currencies of the rates registered before",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "EUR"
+                    ]
+                },
+                "unsupported": {
+                    "description": "quote currencies we or the providers don't support",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "XYZ"
+                    ]
+                }
+            }
+        },
+        "apiserver.listRatesResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "offset": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Rate"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "apiserver.problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "RATE_NOT_FOUND"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "row not found"
+                },
+                "errors": {
+                    "description": "why the parameters are invalid by their names",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/v1/convert"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "c19153ba-0f3d-4b48-96ea-6b9bd74e8875"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "There is no record of the exchange rate"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:currency-api:error:RATE_NOT_FOUND"
+                }
+            }
+        },
+        "apiserver.rateHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string",
+                    "example": "2019-11-10T00:00:00Z"
+                },
+                "first_currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "interval": {
+                    "description": "omitted for the raw points",
+                    "type": "string",
+                    "example": "hour"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RatePoint"
+                    }
+                },
+                "second_currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2019-11-09T00:00:00Z"
+                }
+            }
+        },
+        "apiserver.updateRateQuery": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "j.doe"
+                },
+                "pinned_until": {
+                    "type": "string",
+                    "example": "2019-11-10T21:21:46+00:00"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "fixed by the treasury for the end of the quarter"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "75.4"
+                }
+            }
+        },
+        "cachestore.Stats": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "hits": {
+                    "type": "integer",
+                    "example": 1024
+                },
+                "misses": {
+                    "type": "integer",
+                    "example": 16
+                },
+                "size": {
+                    "description": "number of the cached lookups",
+                    "type": "integer",
+                    "example": 12
                 }
             }
         },
@@ -220,13 +1111,102 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2019-11-09T21:21:46+00:00"
                 },
+                "pinned_until": {
+                    "description": "the rate isn't updated automatically until then",
+                    "type": "string",
+                    "example": "2019-11-10T21:21:46+00:00"
+                },
+                "second_currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "freecurrencyapi,exchangerateapi"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "75.4"
+                }
+            }
+        },
+        "model.RateAuditEntry": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "j.doe"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2019-11-09T21:21:46+00:00"
+                },
+                "first_currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "pinned_until": {
+                    "type": "string",
+                    "example": "2019-11-10T21:21:46+00:00"
+                },
+                "rate_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "fixed by the treasury for the end of the quarter"
+                },
                 "second_currency": {
                     "type": "string",
                     "example": "USD"
                 },
                 "value": {
-                    "type": "number",
-                    "example": 75.4
+                    "type": "string",
+                    "example": "75.4"
+                }
+            }
+        },
+        "model.RatePoint": {
+            "type": "object",
+            "properties": {
+                "source": {
+                    "type": "string",
+                    "example": "freecurrencyapi"
+                },
+                "time": {
+                    "type": "string",
+                    "example": "2019-11-09T21:21:46+00:00"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "75.4"
+                }
+            }
+        },
+        "provider.Status": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "description": "consecutive failures",
+                    "type": "integer",
+                    "example": 0
+                },
+                "name": {
+                    "type": "string",
+                    "example": "freecurrencyapi"
+                },
+                "opened_at": {
+                    "type": "string",
+                    "example": "2019-11-09T21:21:46+00:00"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "closed"
                 }
             }
         }
@@ -1,41 +1,270 @@
 package apiserver
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"github.com/go-redis/redis/v8"
 	"github.com/sirupsen/logrus"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/config"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/leader"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/migrate"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider/exchangerateapi"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider/freecurrencyapi"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider/testprovider"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/cachestore"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/redisstore"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/sqlitestore"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/sqlstore"
+	"github.com/tmrrwnxtsn/currency-conversion-api/migrations"
 	"net/http"
+	"sync"
+	"time"
 )
 
-// Start ...
-func Start(cfg *config.Config) error {
-	db, err := newDB(cfg.DatabaseURL)
+// migrationLockKey is the key of the advisory lock the instances migrate the Postgres database under.
+const migrationLockKey int64 = 1918989414
+
+// Start runs the API server and the rate updater until the context is cancelled, then shuts them down gracefully.
+// The database and Redis are closed only after both have stopped.
+func Start(ctx context.Context, cfg *config.Config) error {
+	logger := logrus.New()
+
+	db, err := OpenDB(ctx, cfg.DatabaseURL)
 	if err != nil {
 		return err
 	}
 	defer db.Close()
 
-	store := sqlstore.New(db)
-	logger := logrus.New()
+	if err = migrateDB(ctx, cfg, db, logger); err != nil {
+		return err
+	}
+
+	rateProvider, err := newRateProvider(cfg)
+	if err != nil {
+		return err
+	}
+
+	var redisClient *redis.Client
+	if cfg.RedisURL != "" {
+		if redisClient, err = newRedisClient(ctx, cfg.RedisURL); err != nil {
+			return err
+		}
+		defer redisClient.Close()
+	}
+
+	store, err := newStore(ctx, cfg, db, redisClient)
+	if err != nil {
+		return err
+	}
+
+	backgroundCtx, stopBackground := context.WithCancel(ctx)
+	defer stopBackground()
+
+	var wg sync.WaitGroup
+
+	// only the instance holding the lock updates the rates, the others take over if it goes down
+	updater := newRateUpdater(cfg, store, rateProvider, logger)
+	elector := leader.NewElector(newLeaderLock(cfg, db), time.Second*time.Duration(cfg.LeaderCheckInterval), logger)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		elector.Run(backgroundCtx, updater.Start)
+	}()
+
+	// the rates cached in memory are invalidated when other instances write them
+	if cached, ok := store.(*cachestore.Store); ok && redisClient != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := redisstore.Subscribe(backgroundCtx, redisClient, cached.Invalidate); err != nil {
+				logger.Errorf("error occurred while subscribing to the rate invalidations: %s", err.Error())
+			}
+		}()
+	}
 
-	updater := newRateUpdater(cfg, store, logger)
-	go updater.Start()
+	httpServer := &http.Server{
+		Addr:    cfg.BindAddr,
+		Handler: newServer(cfg, store, rateProvider, logger),
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		logger.Info("shutting down the API server...")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(cfg.ShutdownTimeout))
+		defer cancel()
+
+		err = httpServer.Shutdown(shutdownCtx)
+	}
 
-	srv := newServer(cfg, store, logger)
+	stopBackground()
+	wg.Wait()
 
-	return http.ListenAndServe(cfg.BindAddr, srv)
+	return err
 }
 
-func newDB(databaseURL string) (*sql.DB, error) {
+// OpenDB opens the SQLite database if the URL has the sqlite:// scheme, the Postgres one otherwise.
+func OpenDB(ctx context.Context, databaseURL string) (*sql.DB, error) {
+	if sqlitestore.IsURL(databaseURL) {
+		return sqlitestore.Open(ctx, databaseURL)
+	}
+
 	db, err := sql.Open("postgres", databaseURL)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = db.Ping(); err != nil {
+	if err = db.PingContext(ctx); err != nil {
+		_ = db.Close()
 		return nil, err
 	}
 
 	return db, nil
 }
+
+// NewMigrator creates the migrator of the database opened by OpenDB. The instances sharing a Postgres database
+// migrate it one at a time, a SQLite database is used by a single instance.
+func NewMigrator(databaseURL string, db *sql.DB) (*migrate.Migrator, error) {
+	if sqlitestore.IsURL(databaseURL) {
+		return migrate.New(db, migrations.SQLite())
+	}
+
+	migrator, err := migrate.New(db, migrations.Postgres())
+	if err != nil {
+		return nil, err
+	}
+	migrator.SetLock(sqlstore.NewAdvisoryLock(db, migrationLockKey))
+
+	return migrator, nil
+}
+
+// migrateDB applies the pending migrations if auto_migrate is set, otherwise it only warns about them.
+func migrateDB(ctx context.Context, cfg *config.Config, db *sql.DB, logger *logrus.Logger) error {
+	migrator, err := NewMigrator(cfg.DatabaseURL, db)
+	if err != nil {
+		return err
+	}
+
+	if !cfg.AutoMigrate {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+
+		if pending > 0 {
+			logger.Warnf("there are %d pending migrations, run \"apiserver migrate up\" to apply them", pending)
+		}
+
+		return nil
+	}
+
+	applied, err := migrator.Up(ctx)
+	for _, migration := range applied {
+		logger.Infof("applied migration %d_%s", migration.Version, migration.Name)
+	}
+
+	return err
+}
+
+func newRedisClient(ctx context.Context, redisURL string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(opts)
+	if err = client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// newStore creates the store caching the rates in memory and, if there is a Redis client, in Redis,
+// where the cache is shared by all the instances.
+func newStore(ctx context.Context, cfg *config.Config, db *sql.DB, redisClient *redis.Client) (store.Store, error) {
+	var st store.Store = sqlstore.New(db)
+	if sqlitestore.IsURL(cfg.DatabaseURL) {
+		st = sqlitestore.New(db)
+	}
+
+	if cfg.CacheTTL <= 0 {
+		return st, nil
+	}
+
+	ttl := time.Second * time.Duration(cfg.CacheTTL)
+	if redisClient != nil {
+		st = redisstore.New(st, redisClient, ttl)
+	}
+
+	cached := cachestore.New(st, ttl)
+	if err := cached.Warm(ctx); err != nil {
+		return nil, err
+	}
+
+	return cached, nil
+}
+
+// newLeaderLock returns the lock electing the instance updating the rates,
+// a SQLite database is used by a single instance, which is always the leader.
+func newLeaderLock(cfg *config.Config, db *sql.DB) leader.Lock {
+	if sqlitestore.IsURL(cfg.DatabaseURL) {
+		return leader.Local{}
+	}
+
+	return sqlstore.NewAdvisoryLock(db, cfg.LeaderLockKey)
+}
+
+func newRateProvider(cfg *config.Config) (provider.RateProvider, error) {
+	providers := make([]provider.RateProvider, 0, len(cfg.RateProviders))
+	weights := make(map[string]float64)
+
+	for _, name := range cfg.RateProviders {
+		providerCfg := cfg.Providers[name]
+		timeout := time.Second * time.Duration(providerCfg.Timeout)
+
+		var p provider.RateProvider
+		switch name {
+		case freecurrencyapi.Name:
+			p = freecurrencyapi.New(cfg.CurrencyAPIKey, providerCfg.URL, timeout)
+		case exchangerateapi.Name:
+			p = exchangerateapi.New(providerCfg.URL, timeout)
+		case testprovider.Name:
+			p = testprovider.New()
+		default:
+			return nil, fmt.Errorf("unknown rate provider: %s", name)
+		}
+
+		if providerCfg.RPS > 0 || providerCfg.DailyQuota > 0 {
+			p = provider.NewRateLimited(p, providerCfg.RPS, providerCfg.Burst, providerCfg.DailyQuota)
+		}
+
+		if providerCfg.Retries > 0 {
+			p = provider.NewRetrying(
+				p, providerCfg.Retries,
+				time.Millisecond*time.Duration(providerCfg.RetryDelay), time.Millisecond*time.Duration(providerCfg.RetryMaxDelay),
+			)
+		}
+
+		if providerCfg.BreakerThreshold > 0 {
+			p = provider.NewCircuitBreaker(p, providerCfg.BreakerThreshold, time.Second*time.Duration(providerCfg.BreakerTimeout))
+		}
+
+		providers = append(providers, p)
+		if providerCfg.Weight > 0 {
+			weights[name] = providerCfg.Weight
+		}
+	}
+
+	return provider.NewAggregator(provider.Strategy(cfg.RateAggregation), providers, weights)
+}
@@ -0,0 +1,220 @@
+package apiserver
+
+import (
+	"context"
+	"github.com/shopspring/decimal"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"time"
+)
+
+// inversePrecision is the number of decimal places an inverse rate is calculated with.
+const inversePrecision = 16
+
+// conversionLeg is a conversion between two currencies of a conversion path.
+type conversionLeg struct {
+	CurrencyFrom   string          `json:"currency_from" example:"RUB"`
+	CurrencyTo     string          `json:"currency_to" example:"USD"`
+	Rate           decimal.Decimal `json:"rate" swaggertype:"string" example:"0.0132"`
+	LastUpdateTime time.Time       `json:"last_update_time" example:"2019-11-09T21:21:46+00:00"`
+	Inverse        bool            `json:"inverse" example:"true"` // the rate of the reverse pair was inverted
+}
+
+// conversionRate is the rate a value is converted at.
+type conversionRate struct {
+	Value          decimal.Decimal
+	LastUpdateTime time.Time // the oldest one of the legs
+	Inverse        bool      // the rate of the reverse pair was inverted for at least one leg
+	Legs           []*conversionLeg
+}
+
+type converter struct {
+	store   store.Store
+	maxHops int
+}
+
+func newConverter(store store.Store, maxHops int) *converter {
+	return &converter{
+		store:   store,
+		maxHops: maxHops,
+	}
+}
+
+// findRate finds the rate for converting from one currency to another, at the current moment
+// if at is nil. The rate of the reverse pair is inverted if the direct pair isn't stored, and if
+// neither of them is, the rate is calculated through the shortest chain of the stored pairs.
+func (c *converter) findRate(ctx context.Context, currencyFrom, currencyTo string, at *time.Time) (*conversionRate, error) {
+	leg, err := c.findLeg(ctx, currencyFrom, currencyTo, at)
+	if err == nil {
+		return newConversionRate([]*conversionLeg{leg}), nil
+	}
+
+	if err != store.ErrRowNotFound {
+		return nil, err
+	}
+
+	legs, err := c.findPath(ctx, currencyFrom, currencyTo, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return newConversionRate(legs), nil
+}
+
+func newConversionRate(legs []*conversionLeg) *conversionRate {
+	rate := &conversionRate{
+		Value:          decimal.NewFromInt(1),
+		LastUpdateTime: legs[0].LastUpdateTime,
+		Legs:           legs,
+	}
+
+	for _, leg := range legs {
+		rate.Value = rate.Value.Mul(leg.Rate)
+		rate.Inverse = rate.Inverse || leg.Inverse
+		if leg.LastUpdateTime.Before(rate.LastUpdateTime) {
+			rate.LastUpdateTime = leg.LastUpdateTime
+		}
+	}
+
+	return rate
+}
+
+// edge is a stored pair leading from one of its currencies to the other one.
+type edge struct {
+	currencyTo string
+	rate       *model.Rate
+}
+
+// findPath searches for the shortest chain of legs over the stored pairs in any direction that leads
+// from one currency to another in no more than maxHops conversions. If at isn't nil, only the pairs
+// that have a rate recorded at or before it connect the currencies.
+func (c *converter) findPath(ctx context.Context, currencyFrom, currencyTo string, at *time.Time) ([]*conversionLeg, error) {
+	if c.maxHops < 2 {
+		return nil, store.ErrRowNotFound
+	}
+
+	rates, err := c.store.Rate().FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the direct pairs go first, so that they are preferred to the reverse ones like in findLeg
+	graph := make(map[string][]edge)
+	for _, rate := range rates {
+		graph[rate.FirstCurrency] = append(graph[rate.FirstCurrency], edge{rate.SecondCurrency, rate})
+	}
+	for _, rate := range rates {
+		graph[rate.SecondCurrency] = append(graph[rate.SecondCurrency], edge{rate.FirstCurrency, rate})
+	}
+
+	previous := map[string]*conversionLeg{currencyFrom: nil}
+	frontier := []string{currencyFrom}
+	for hops := 0; hops < c.maxHops && len(frontier) > 0; hops++ {
+		var next []string
+		for _, cur := range frontier {
+			for _, e := range graph[cur] {
+				if _, visited := previous[e.currencyTo]; visited {
+					continue
+				}
+
+				// the history is looked up only for the pairs the search gets to
+				leg, err := c.edgeLeg(ctx, cur, e, at)
+				if err == store.ErrRowNotFound {
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+				previous[e.currencyTo] = leg
+
+				if e.currencyTo == currencyTo {
+					return buildPath(previous, currencyFrom, currencyTo), nil
+				}
+				next = append(next, e.currencyTo)
+			}
+		}
+		frontier = next
+	}
+
+	return nil, store.ErrRowNotFound
+}
+
+// edgeLeg returns the leg from the currency over the edge at the rate recorded as of at, or at the stored one if at is nil.
+func (c *converter) edgeLeg(ctx context.Context, currencyFrom string, e edge, at *time.Time) (*conversionLeg, error) {
+	point := &model.RatePoint{
+		Value:  e.rate.Value,
+		Time:   e.rate.LastUpdateTime,
+		Source: e.rate.Source,
+	}
+
+	if at != nil {
+		var err error
+		if point, err = c.store.RateHistory().FindAsOf(ctx, e.rate.FirstCurrency, e.rate.SecondCurrency, *at); err != nil {
+			return nil, err
+		}
+	}
+
+	return newConversionLeg(currencyFrom, e.currencyTo, point, e.rate.FirstCurrency != currencyFrom), nil
+}
+
+func buildPath(previous map[string]*conversionLeg, currencyFrom, currencyTo string) []*conversionLeg {
+	var legs []*conversionLeg
+	for cur := currencyTo; cur != currencyFrom; cur = previous[cur].CurrencyFrom {
+		legs = append([]*conversionLeg{previous[cur]}, legs...)
+	}
+
+	return legs
+}
+
+// findLeg finds the rate of the pair, inverting the rate of the reverse pair if necessary.
+func (c *converter) findLeg(ctx context.Context, currencyFrom, currencyTo string, at *time.Time) (*conversionLeg, error) {
+	point, err := c.findPoint(ctx, currencyFrom, currencyTo, at)
+	if err == nil {
+		return newConversionLeg(currencyFrom, currencyTo, point, false), nil
+	}
+
+	if err != store.ErrRowNotFound {
+		return nil, err
+	}
+
+	point, err = c.findPoint(ctx, currencyTo, currencyFrom, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return newConversionLeg(currencyFrom, currencyTo, point, true), nil
+}
+
+// newConversionLeg creates the leg at the rate of the point, which is inverted if the point is of the reverse pair.
+func newConversionLeg(currencyFrom, currencyTo string, point *model.RatePoint, inverse bool) *conversionLeg {
+	leg := &conversionLeg{
+		CurrencyFrom:   currencyFrom,
+		CurrencyTo:     currencyTo,
+		Rate:           point.Value,
+		LastUpdateTime: point.Time,
+		Inverse:        inverse,
+	}
+
+	if inverse {
+		leg.Rate = decimal.NewFromInt(1).DivRound(point.Value, inversePrecision)
+	}
+
+	return leg
+}
+
+func (c *converter) findPoint(ctx context.Context, firstCurrency, secondCurrency string, at *time.Time) (*model.RatePoint, error) {
+	if at != nil {
+		return c.store.RateHistory().FindAsOf(ctx, firstCurrency, secondCurrency, *at)
+	}
+
+	rate, err := c.store.Rate().FindByCurrencies(ctx, firstCurrency, secondCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.RatePoint{
+		Value:  rate.Value,
+		Time:   rate.LastUpdateTime,
+		Source: rate.Source,
+	}, nil
+}
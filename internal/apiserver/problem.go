@@ -0,0 +1,166 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"net/http"
+	"strings"
+)
+
+// problemContentType is the media type of the error responses, see RFC 7807.
+const problemContentType = "application/problem+json"
+
+// errorCode is a machine-readable code of an API error, the clients branch on it instead of the message.
+type errorCode string
+
+const (
+	codeInvalidRequest      errorCode = "INVALID_REQUEST"
+	codeValidationFailed    errorCode = "VALIDATION_FAILED"
+	codeUnsupportedCurrency errorCode = "UNSUPPORTED_CURRENCY"
+	codeRateNotFound        errorCode = "RATE_NOT_FOUND"
+	codeRateAlreadyExists   errorCode = "RATE_ALREADY_EXISTS"
+	codeStaleRate           errorCode = "STALE_RATE"
+	codeUpstreamUnavailable errorCode = "UPSTREAM_UNAVAILABLE"
+	codeCacheDisabled       errorCode = "CACHE_DISABLED"
+	codeInternalError       errorCode = "INTERNAL_ERROR"
+)
+
+// errorType is the HTTP status and the summary every error of a code has.
+type errorType struct {
+	status int
+	title  string
+}
+
+var errorTypes = map[errorCode]errorType{
+	codeInvalidRequest:      {http.StatusBadRequest, "The request is malformed or misses required parameters"},
+	codeValidationFailed:    {http.StatusUnprocessableEntity, "One or more parameters are invalid"},
+	codeUnsupportedCurrency: {http.StatusUnprocessableEntity, "The currency isn't supported"},
+	codeRateNotFound:        {http.StatusNotFound, "There is no record of the exchange rate"},
+	codeRateAlreadyExists:   {http.StatusConflict, "The exchange rate record already exists"},
+	codeStaleRate:           {http.StatusServiceUnavailable, "The exchange rate is stale"},
+	codeUpstreamUnavailable: {http.StatusServiceUnavailable, "The exchange rate provider is unavailable"},
+	codeCacheDisabled:       {http.StatusNotFound, "The rate cache is disabled"},
+	codeInternalError:       {http.StatusInternalServerError, "Internal server error"},
+}
+
+// apiError is an error the API responds with.
+type apiError struct {
+	code   errorCode
+	detail string
+	fields map[string]string // why the parameters are invalid by their names
+	err    error
+}
+
+// newAPIError creates an error, the detail is also the reason each of the params is invalid for.
+func newAPIError(code errorCode, detail string, params ...string) *apiError {
+	e := &apiError{
+		code:   code,
+		detail: detail,
+	}
+
+	if len(params) > 0 {
+		e.fields = make(map[string]string, len(params))
+		for _, param := range params {
+			e.fields[param] = detail
+		}
+	}
+
+	return e
+}
+
+func (e *apiError) Error() string {
+	if e.err != nil {
+		return e.detail + ": " + e.err.Error()
+	}
+
+	return e.detail
+}
+
+func (e *apiError) Unwrap() error {
+	return e.err
+}
+
+// wrap returns a copy of the error caused by err.
+func (e *apiError) wrap(err error) *apiError {
+	wrapped := *e
+	wrapped.err = err
+
+	return &wrapped
+}
+
+// problem is an error response, see RFC 7807.
+type problem struct {
+	Type      string            `json:"type" example:"urn:currency-api:error:RATE_NOT_FOUND"`
+	Title     string            `json:"title" example:"There is no record of the exchange rate"`
+	Status    int               `json:"status" example:"404"`
+	Detail    string            `json:"detail,omitempty" example:"row not found"`
+	Instance  string            `json:"instance,omitempty" example:"/api/v1/convert"`
+	Code      errorCode         `json:"code" swaggertype:"string" example:"RATE_NOT_FOUND"`
+	Errors    map[string]string `json:"errors,omitempty"` // why the parameters are invalid by their names
+	RequestID string            `json:"request_id,omitempty" example:"c19153ba-0f3d-4b48-96ea-6b9bd74e8875"`
+}
+
+// newProblem maps the error to the problem, the errors of unknown kinds are internal ones,
+// their details aren't disclosed. Neither are the causes of the API errors of the server, they are only logged.
+func newProblem(err error) *problem {
+	var (
+		apiErr         *apiError
+		validationErrs validation.Errors
+	)
+
+	p := &problem{}
+
+	switch {
+	case errors.As(err, &apiErr):
+		p.Code, p.Detail, p.Errors = apiErr.code, apiErr.Error(), apiErr.fields
+		if errorTypes[p.Code].status >= http.StatusInternalServerError {
+			p.Detail = apiErr.detail
+		}
+	case errors.As(err, &validationErrs):
+		p.Code, p.Detail = codeValidationFailed, err.Error()
+		p.Errors = make(map[string]string, len(validationErrs))
+		for field, fieldErr := range validationErrs {
+			p.Errors[field] = fieldErr.Error()
+		}
+	case errors.Is(err, store.ErrRowNotFound):
+		p.Code, p.Detail = codeRateNotFound, err.Error()
+	case errors.Is(err, store.ErrDuplicate):
+		p.Code, p.Detail = codeRateAlreadyExists, err.Error()
+	case errors.Is(err, store.ErrInvalidRow):
+		p.Code, p.Detail = codeValidationFailed, err.Error()
+	default:
+		p.Code = codeInternalError
+	}
+
+	errType := errorTypes[p.Code]
+	p.Type = "urn:currency-api:error:" + string(p.Code)
+	p.Title = errType.title
+	p.Status = errType.status
+
+	return p
+}
+
+// problem maps the error of the request to the problem and logs the internal ones.
+func (s *server) problem(r *http.Request, err error) *problem {
+	p := newProblem(err)
+	p.Instance = r.URL.Path
+	if id, ok := r.Context().Value(ctxKeyRequestID).(string); ok {
+		p.RequestID = id
+	}
+
+	if p.Status >= http.StatusInternalServerError {
+		s.logger.WithField("request_id", p.RequestID).Errorf("%s: %s", strings.ToLower(p.Title), err.Error())
+	}
+
+	return p
+}
+
+func (s *server) error(w http.ResponseWriter, r *http.Request, err error) {
+	p := s.problem(r, err)
+
+	w.Header().Set("Content-type", problemContentType)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
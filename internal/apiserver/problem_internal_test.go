@@ -0,0 +1,149 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider/testprovider"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/teststore"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewProblem(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedCode   errorCode
+		expectedStatus int
+		expectedFields []string
+	}{
+		{
+			name:           "api error",
+			err:            errWrongTimeRange,
+			expectedCode:   codeValidationFailed,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedFields: []string{"start", "end"},
+		},
+		{
+			name:           "wrapped api error",
+			err:            errUpstreamUnavailable.wrap(errors.New("timeout")),
+			expectedCode:   codeUpstreamUnavailable,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "validation errors",
+			err:            validation.Errors{"value": errors.New("must be positive")},
+			expectedCode:   codeValidationFailed,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedFields: []string{"value"},
+		},
+		{
+			name:           "row not found",
+			err:            store.ErrRowNotFound,
+			expectedCode:   codeRateNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "duplicate",
+			err:            store.ErrDuplicate,
+			expectedCode:   codeRateAlreadyExists,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "unknown",
+			err:            errors.New("connection refused"),
+			expectedCode:   codeInternalError,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newProblem(tc.err)
+			assert.Equal(t, tc.expectedCode, p.Code)
+			assert.Equal(t, tc.expectedStatus, p.Status)
+			assert.NotEmpty(t, p.Title)
+			assert.Equal(t, len(tc.expectedFields), len(p.Errors))
+			for _, field := range tc.expectedFields {
+				assert.Contains(t, p.Errors, field)
+			}
+
+			if tc.expectedCode == codeInternalError {
+				assert.Empty(t, p.Detail)
+			}
+
+			// the causes of the server errors may contain the secrets of the upstreams
+			if tc.expectedStatus >= http.StatusInternalServerError {
+				assert.NotContains(t, p.Detail, "timeout")
+			}
+		})
+	}
+}
+
+func TestServer_Error(t *testing.T) {
+	srv := newServer(TestConfig(t), teststore.New(), testprovider.New(), TestLogger(t))
+
+	r := model.TestRate(t)
+
+	testCases := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedCode   errorCode
+		expectedFields []string
+	}{
+		{
+			name:           "missing params",
+			query:          "",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   codeInvalidRequest,
+		},
+		{
+			name:           "invalid value",
+			query:          "currency_from=" + r.FirstCurrency + "&currency_to=" + r.SecondCurrency + "&value=invalid",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   codeValidationFailed,
+			expectedFields: []string{"value"},
+		},
+		{
+			name:           "unsupported currency",
+			query:          "currency_from=dollar&currency_to=" + r.SecondCurrency + "&value=10",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   codeUnsupportedCurrency,
+			expectedFields: []string{"currency_from"},
+		},
+		{
+			name:           "rate not found",
+			query:          "currency_from=" + r.FirstCurrency + "&currency_to=" + r.SecondCurrency + "&value=10",
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   codeRateNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/convert?"+tc.query, nil)
+
+			srv.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, problemContentType, rec.Header().Get("Content-Type"))
+
+			p := &problem{}
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(p))
+			assert.Equal(t, tc.expectedCode, p.Code)
+			assert.Equal(t, tc.expectedStatus, p.Status)
+			assert.Equal(t, "/api/v1/convert", p.Instance)
+			assert.Equal(t, rec.Header().Get("X-Request-ID"), p.RequestID)
+			assert.Equal(t, len(tc.expectedFields), len(p.Errors))
+			for _, field := range tc.expectedFields {
+				assert.Contains(t, p.Errors, field)
+			}
+		})
+	}
+}
@@ -1,100 +1,144 @@
 package apiserver
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"github.com/sirupsen/logrus"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/config"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
-	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/sqlstore"
-	"net/http"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"strings"
+	"sync"
 	"time"
 )
 
 type rateUpdater struct {
-	config *config.Config
-	store  *sqlstore.Store
-	logger *logrus.Logger
+	config   *config.Config
+	store    store.Store
+	provider provider.RateProvider
+	logger   *logrus.Logger
 }
 
-func newRateUpdater(config *config.Config, store *sqlstore.Store, logger *logrus.Logger) *rateUpdater {
+func newRateUpdater(config *config.Config, store store.Store, provider provider.RateProvider, logger *logrus.Logger) *rateUpdater {
 	return &rateUpdater{
-		config: config,
-		store:  store,
-		logger: logger,
+		config:   config,
+		store:    store,
+		provider: provider,
+		logger:   logger,
 	}
 }
 
-// Start ...
-func (u *rateUpdater) Start() {
-	for range time.Tick(time.Minute * time.Duration(u.config.UpdateInterval)) {
-		rates, err := u.store.Rate().FindAll()
-		if err != nil {
-			u.logger.Errorf("error occurred while getting rates from the db: %s", err.Error())
+// Start updates the rates right away and then every update interval until the context is cancelled,
+// so that an instance taking over the leadership doesn't leave them stale for another interval.
+func (u *rateUpdater) Start(ctx context.Context) {
+	u.update(ctx)
+
+	ticker := time.NewTicker(time.Minute * time.Duration(u.config.UpdateInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			u.update(ctx)
+		}
+	}
+}
+
+// update updates all stored rates once, fetching the exchange rates for every base currency only once.
+// Base currencies are updated concurrently by a pool of workers, the ones not started before
+// the context is cancelled are skipped.
+func (u *rateUpdater) update(ctx context.Context) {
+	rates, err := u.store.Rate().FindAll(ctx)
+	if err != nil {
+		u.logger.Errorf("error occurred while getting rates from the db: %s", err.Error())
+		return
+	}
+
+	ratesByBase := make(map[string][]*model.Rate)
+	for _, rate := range rates {
+		if rate.IsPinned(time.Now()) {
+			u.logger.Infof("%s-%s rate is pinned until %s, skipping", rate.FirstCurrency, rate.SecondCurrency, rate.PinnedUntil.Format(time.RFC3339))
 			continue
 		}
 
-		for _, rate := range rates {
-			response, err := getExchangeRates(u.config.CurrencyAPIKey, rate.FirstCurrency)
-			if err != nil {
-				u.logger.Errorf("error occurred while getting the rate info for the currency %s: %s", rate.FirstCurrency, err.Error())
-				continue
-			}
+		ratesByBase[rate.FirstCurrency] = append(ratesByBase[rate.FirstCurrency], rate)
+	}
 
-			rateUpd := &model.Rate{
-				ID:             rate.ID,
-				FirstCurrency:  rate.FirstCurrency,
-				SecondCurrency: rate.SecondCurrency,
-				Value:          response.Data[rate.SecondCurrency],
-				LastUpdateTime: time.Now(),
-			}
+	workers := u.config.UpdateWorkers
+	if workers < 1 {
+		workers = 1
+	}
 
-			if err = u.store.Rate().Update(rateUpd); err != nil {
-				u.logger.Errorf("error occurred while updating %s-%s rate: %s", rate.FirstCurrency, rate.SecondCurrency, err.Error())
-				continue
-			}
+	bases := make(chan string)
 
-			u.logger.Infof("%s-%s rate was successfully updated!", rate.FirstCurrency, rate.SecondCurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for base := range bases {
+				u.updateBase(ctx, base, ratesByBase[base])
+			}
+		}()
+	}
 
-			time.Sleep(5 * time.Second)
+	for base := range ratesByBase {
+		if ctx.Err() != nil {
+			u.logger.Info("rates update was interrupted")
+			break
 		}
+
+		bases <- base
 	}
-}
+	close(bases)
 
-type getExchangeRatesQuery struct {
-	BaseCurrency string `json:"base_currency"`
+	wg.Wait()
 }
 
-type getExchangeRatesResponse struct {
-	Query getExchangeRatesQuery `json:"query"`
-	Data  map[string]float32    `json:"data"`
-}
+// updateBase updates the rates of the base currency in a single transaction.
+func (u *rateUpdater) updateBase(ctx context.Context, base string, rates []*model.Rate) {
+	response, err := u.provider.GetExchangeRates(ctx, base)
+	if err != nil {
+		u.logger.Errorf("error occurred while getting the rate info for the currency %s: %s", base, err.Error())
+		return
+	}
+
+	ratesUpd := make([]*model.Rate, 0, len(rates))
+	for _, rate := range rates {
+		quote, ok := response.Rates[rate.SecondCurrency]
+		if !ok {
+			u.logger.Errorf("error occurred while updating %s-%s rate: no quote for the currency %s", rate.FirstCurrency, rate.SecondCurrency, rate.SecondCurrency)
+			continue
+		}
 
-func getExchangeRates(currencyApiKey, baseCurrency string) (*getExchangeRatesResponse, error) {
-	// request to the external currency conversion API for the rates
-	rateApiRequestUrl := fmt.Sprintf(
-		"https://freecurrencyapi.net/api/v2/latest?apikey=%s&base_currency=%s",
-		currencyApiKey,
-		baseCurrency,
-	)
+		rateUpd := &model.Rate{
+			ID:             rate.ID,
+			FirstCurrency:  rate.FirstCurrency,
+			SecondCurrency: rate.SecondCurrency,
+			Value:          quote.Value,
+			LastUpdateTime: time.Now(),
+			Source:         strings.Join(quote.Sources, ","),
+		}
 
-	res, err := http.Get(rateApiRequestUrl)
-	if err != nil {
-		return nil, err
+		// an invalid quote mustn't keep the other rates of the base from being updated
+		if err = rateUpd.Validate(); err != nil {
+			u.logger.Errorf("error occurred while updating %s-%s rate: %s", rate.FirstCurrency, rate.SecondCurrency, err.Error())
+			continue
+		}
+
+		ratesUpd = append(ratesUpd, rateUpd)
 	}
-	defer res.Body.Close()
 
-	if res.StatusCode > 299 {
-		return nil, fmt.Errorf(
-			"error occurred while updating rate for the currency %s: response failed with status code: %d and\nbody: %s\n",
-			baseCurrency, res.StatusCode, res.Body,
-		)
+	if len(ratesUpd) == 0 {
+		return
 	}
 
-	response := &getExchangeRatesResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
-		return nil, err
+	if err = u.store.Rate().UpdateMany(ctx, ratesUpd); err != nil {
+		u.logger.Errorf("error occurred while updating %s rates: %s", base, err.Error())
+		return
 	}
 
-	return response, nil
+	u.logger.Infof("%d %s rates were successfully updated!", len(ratesUpd), base)
 }
@@ -0,0 +1,169 @@
+package apiserver
+
+import (
+	"context"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider/testprovider"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/teststore"
+	"sync"
+	"testing"
+	"time"
+)
+
+type countingProvider struct {
+	provider.RateProvider
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (p *countingProvider) GetExchangeRates(ctx context.Context, baseCurrency string) (*provider.ExchangeRates, error) {
+	p.mu.Lock()
+	p.calls[baseCurrency]++
+	p.mu.Unlock()
+
+	return p.RateProvider.GetExchangeRates(ctx, baseCurrency)
+}
+
+func TestRateUpdater_Update(t *testing.T) {
+	st := teststore.New()
+	p := &countingProvider{RateProvider: testprovider.New(), calls: make(map[string]int)}
+	updater := newRateUpdater(TestConfig(t), st, p, TestLogger(t))
+
+	expiredPin := time.Now().Add(-time.Hour)
+	var rates []*model.Rate
+	for _, pair := range [][2]string{{"USD", "RUB"}, {"USD", "EUR"}, {"USD", "JPY"}, {"EUR", "GBP"}, {"CNY", "RUB"}} {
+		r := model.TestRate(t)
+		r.FirstCurrency, r.SecondCurrency = pair[0], pair[1]
+		r.Value = decimal.NewFromInt(1)
+		_ = st.Rate().Create(context.Background(), r)
+		rates = append(rates, r)
+	}
+	rates[2].Source = manualRateSource
+	rates[2].PinnedUntil = &expiredPin
+	_ = st.Rate().Update(context.Background(), rates[2])
+
+	updater.update(context.Background())
+
+	assert.Equal(t, map[string]int{"USD": 1, "EUR": 1, "CNY": 1}, p.calls)
+
+	for _, r := range rates {
+		expected, err := testprovider.New().GetExchangeRates(context.Background(), r.FirstCurrency)
+		assert.NoError(t, err)
+
+		rate, err := st.Rate().Find(context.Background(), r.ID)
+		assert.NoError(t, err)
+		assert.True(t, expected.Rates[r.SecondCurrency].Value.Equal(rate.Value))
+		assert.Equal(t, testprovider.Name, rate.Source)
+		assert.Nil(t, rate.PinnedUntil)
+	}
+}
+
+func TestRateUpdater_Update_Pinned(t *testing.T) {
+	st := teststore.New()
+	updater := newRateUpdater(TestConfig(t), st, testprovider.New(), TestLogger(t))
+
+	pinnedUntil := time.Now().Add(time.Hour)
+	r := model.TestRate(t)
+	r.Source = manualRateSource
+	r.PinnedUntil = &pinnedUntil
+	_ = st.Rate().Create(context.Background(), r)
+
+	updater.update(context.Background())
+
+	rate, err := st.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "121.41", rate.Value.String())
+	assert.Equal(t, manualRateSource, rate.Source)
+}
+
+// zeroingProvider quotes the currency at zero.
+type zeroingProvider struct {
+	provider.RateProvider
+	currency string
+}
+
+func (p zeroingProvider) GetExchangeRates(ctx context.Context, baseCurrency string) (*provider.ExchangeRates, error) {
+	res, err := p.RateProvider.GetExchangeRates(ctx, baseCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := res.Rates[p.currency]
+	quote.Value = decimal.Zero
+	res.Rates[p.currency] = quote
+
+	return res, nil
+}
+
+func TestRateUpdater_Update_InvalidQuote(t *testing.T) {
+	st := teststore.New()
+	updater := newRateUpdater(TestConfig(t), st, zeroingProvider{testprovider.New(), "RUB"}, TestLogger(t))
+
+	var rates []*model.Rate
+	for _, quote := range []string{"RUB", "EUR"} {
+		r := model.TestRate(t)
+		r.SecondCurrency = quote
+		r.Value = decimal.NewFromInt(1)
+		_ = st.Rate().Create(context.Background(), r)
+		rates = append(rates, r)
+	}
+
+	updater.update(context.Background())
+
+	rate, err := st.Rate().Find(context.Background(), rates[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", rate.Value.String())
+
+	rate, err = st.Rate().Find(context.Background(), rates[1].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, testprovider.Name, rate.Source)
+	assert.False(t, rate.Value.Equal(decimal.NewFromInt(1)))
+}
+
+func TestRateUpdater_Update_Cancelled(t *testing.T) {
+	st := teststore.New()
+	p := &countingProvider{RateProvider: testprovider.New(), calls: make(map[string]int)}
+	updater := newRateUpdater(TestConfig(t), st, p, TestLogger(t))
+
+	_ = st.Rate().Create(context.Background(), model.TestRate(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	updater.update(ctx)
+
+	assert.Empty(t, p.calls)
+}
+
+func TestRateUpdater_Start(t *testing.T) {
+	st := teststore.New()
+	_ = st.Rate().Create(context.Background(), model.TestRate(t))
+
+	prov := &countingProvider{RateProvider: testprovider.New(), calls: make(map[string]int)}
+	updater := newRateUpdater(TestConfig(t), st, prov, TestLogger(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		updater.Start(ctx)
+		close(stopped)
+	}()
+
+	// the rates are updated without waiting for the update interval
+	assert.Eventually(t, func() bool {
+		prov.mu.Lock()
+		defer prov.mu.Unlock()
+		return prov.calls["USD"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("updater didn't stop after the context was cancelled")
+	}
+}
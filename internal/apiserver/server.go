@@ -8,13 +8,18 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 	httpSwagger "github.com/swaggo/http-swagger"
 	_ "github.com/tmrrwnxtsn/currency-conversion-api/docs"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/config"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/currency"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/cachestore"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -23,26 +28,62 @@ import (
 const ctxKeyRequestID ctxKey = iota
 
 var (
-	errMissingRequiredParams = errors.New("one or more required parameters are missing")
-	errWrongValueParam       = errors.New("parameter 'value' is wrong")
-	errIdenticalCurrencies   = errors.New("the exchange rate should contain information about different currencies")
+	errMissingRequiredParams = newAPIError(codeInvalidRequest, "one or more required parameters are missing")
+	errInvalidPayload        = newAPIError(codeInvalidRequest, "invalid payload")
+	errWrongValueParam       = newAPIError(codeValidationFailed, "parameter 'value' is wrong", "value")
+	errIdenticalCurrencies   = newAPIError(codeValidationFailed, "the exchange rate should contain information about different currencies", "first_currency", "second_currency")
+	errWrongTimeRange        = newAPIError(codeValidationFailed, "parameters 'start' and 'end' should be RFC 3339 timestamps, 'start' should not be after 'end'", "start", "end")
+	errWrongIntervalParam    = newAPIError(codeValidationFailed, "parameter 'interval' should be one of: raw, hour, day", "interval")
+	errWrongRoundingParam    = newAPIError(codeValidationFailed, "parameter 'rounding' should be one of: half_even, half_up, down, up", "rounding")
+	errWrongIDParam          = newAPIError(codeInvalidRequest, "parameter 'id' is wrong", "id")
+	errWrongPinnedUntil      = newAPIError(codeValidationFailed, "parameter 'pinned_until' should be in the future", "pinned_until")
+	errMissingPinAuthor      = newAPIError(codeInvalidRequest, "parameters 'author' and 'reason' are required to pin the exchange rate", "author", "reason")
+	errWrongPaginationParams = newAPIError(codeValidationFailed, "parameter 'limit' should be an integer from 1 to 500 and parameter 'offset' should be a non-negative integer", "limit", "offset")
+	errWrongAtParam          = newAPIError(codeValidationFailed, "parameter 'at' should be an RFC 3339 timestamp and parameter 'date' should be a YYYY-MM-DD date, only one of them can be specified", "at", "date")
+	errWrongMaxAgeParam      = newAPIError(codeValidationFailed, "parameter 'max_age' should be a non-negative number of seconds", "max_age")
+	errWrongOnStaleParam     = newAPIError(codeValidationFailed, "parameter 'on_stale' should be one of: flag, reject", "on_stale")
+	errStaleRate             = newAPIError(codeStaleRate, "the exchange rate is older than the max age")
+	errCacheDisabled         = newAPIError(codeCacheDisabled, "the rate cache is disabled")
+	errUpstreamUnavailable   = newAPIError(codeUpstreamUnavailable, "rate provider is unavailable")
+	errAmbiguousQuotes       = newAPIError(codeValidationFailed, "either 'quote_currencies' or 'all' should be specified", "quote_currencies", "all")
+	errWrongBatchSize        = newAPIError(codeValidationFailed, fmt.Sprintf("the batch should contain from 1 to %d items", maxBatchItems))
+)
+
+const (
+	// defaultHistoryPeriod is used when the start of the rate history period isn't specified.
+	defaultHistoryPeriod = 24 * time.Hour
+
+	defaultRatesLimit = 50
+	maxRatesLimit     = 500
+
+	// maxBatchItems is the max number of conversions in a batch.
+	maxBatchItems = 1000
+
+	// manualRateSource is the source of the rates set through the API.
+	manualRateSource = "manual"
 )
 
 type ctxKey int8
 
 type server struct {
-	config *config.Config
-	router *mux.Router
-	logger *logrus.Logger
-	store  store.Store
+	config    *config.Config
+	router    *mux.Router
+	logger    *logrus.Logger
+	store     store.Store
+	provider  provider.RateProvider
+	converter *converter
+	staleness *staleness
 }
 
-func newServer(config *config.Config, store store.Store, logger *logrus.Logger) *server {
+func newServer(config *config.Config, store store.Store, provider provider.RateProvider, logger *logrus.Logger) *server {
 	srv := &server{
-		router: mux.NewRouter(),
-		logger: logger,
-		store:  store,
-		config: config,
+		router:    mux.NewRouter(),
+		logger:    logger,
+		store:     store,
+		provider:  provider,
+		converter: newConverter(store, config.MaxConversionHops),
+		staleness: newStaleness(config),
+		config:    config,
 	}
 
 	srv.configureRouter()
@@ -59,14 +100,25 @@ func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func (s *server) configureRouter() {
 	s.router.Use(s.setRequestID)
 	s.router.Use(s.logRequest)
+	s.router.Use(s.setTimeout)
 	s.router.Use(handlers.CORS(
 		handlers.AllowedOrigins([]string{"*"}),
 		handlers.AllowedHeaders([]string{"*"}),
 		handlers.AllowedMethods([]string{"*"}),
 	))
 
+	s.router.HandleFunc("/api/v1/rates", s.handleListRates()).Methods("GET")
+	s.router.HandleFunc("/api/v1/rates", s.handleCreateRates()).Methods("POST")
 	s.router.HandleFunc("/api/v1/rate", s.handleCreateRate()).Methods("POST")
+	s.router.HandleFunc("/api/v1/rate/{id:[0-9]+}", s.handleGetRate()).Methods("GET")
+	s.router.HandleFunc("/api/v1/rate/{id:[0-9]+}", s.handleUpdateRate()).Methods("PUT")
+	s.router.HandleFunc("/api/v1/rate/{id:[0-9]+}", s.handleDeleteRate()).Methods("DELETE")
+	s.router.HandleFunc("/api/v1/rate/{id:[0-9]+}/audit", s.handleGetRateAudit()).Methods("GET")
+	s.router.HandleFunc("/api/v1/rate/{from}/{to}/history", s.handleGetRateHistory()).Methods("GET")
 	s.router.HandleFunc("/api/v1/convert", s.handleConvertCurrency()).Methods("GET")
+	s.router.HandleFunc("/api/v1/convert/batch", s.handleConvertBatch()).Methods("POST")
+	s.router.HandleFunc("/api/v1/providers", s.handleGetProviders()).Methods("GET")
+	s.router.HandleFunc("/api/v1/cache/stats", s.handleGetCacheStats()).Methods("GET")
 
 	// swagger documentation
 	s.router.PathPrefix("/docs/").Handler(httpSwagger.WrapHandler)
@@ -80,6 +132,22 @@ func (s server) setRequestID(next http.Handler) http.Handler {
 	})
 }
 
+// setTimeout cancels the context of the request, and so the DB queries and upstream calls it's used for,
+// after the request timeout.
+func (s *server) setTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.config.RequestTimeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*time.Duration(s.config.RequestTimeout))
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func (s *server) logRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger := s.logger.WithFields(logrus.Fields{
@@ -125,56 +193,71 @@ type createRateQuery struct {
 // @Produce      json
 // @Param        input  body      createRateQuery  true  "An exchange rate information"
 // @Success      201    {object}  model.Rate       "Ok"
-// @Failure      400    {object}  errorResponse    "Missing parameters or invalid payload"
-// @Failure      409    {object}  errorResponse    "An exchange rate record with these currencies already exists"
-// @Failure      422    {object}  errorResponse    "Invalid parameters"
-// @Failure      500    {object}  errorResponse
+// @Failure      400    {object}  problem          "Missing parameters or invalid payload"
+// @Failure      409    {object}  problem          "An exchange rate record with these currencies already exists"
+// @Failure      422    {object}  problem          "Invalid parameters or unsupported currencies"
+// @Failure      500    {object}  problem
+// @Failure      503    {object}  problem  "The exchange rate providers are unavailable"
 // @Router       /rate [post]
 func (s *server) handleCreateRate() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		req := &createRateQuery{}
 		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
-			s.error(w, http.StatusBadRequest, err)
+			s.error(w, r, errInvalidPayload.wrap(err))
 			return
 		}
 
+		req.FirstCurrency = strings.ToUpper(req.FirstCurrency)
+		req.SecondCurrency = strings.ToUpper(req.SecondCurrency)
+
 		if req.FirstCurrency == "" || req.SecondCurrency == "" {
-			s.error(w, http.StatusBadRequest, errMissingRequiredParams)
+			s.error(w, r, errMissingRequiredParams)
 			return
 		}
 
 		if req.FirstCurrency == req.SecondCurrency {
-			s.error(w, http.StatusUnprocessableEntity, errIdenticalCurrencies)
+			s.error(w, r, errIdenticalCurrencies)
+			return
+		}
+
+		if err := checkCurrencies(map[string]string{
+			"first_currency":  req.FirstCurrency,
+			"second_currency": req.SecondCurrency,
+		}); err != nil {
+			s.error(w, r, err)
 			return
 		}
 
-		rate, _ := s.store.Rate().FindByCurrencies(strings.ToUpper(req.FirstCurrency), strings.ToUpper(req.SecondCurrency))
+		rate, _ := s.store.Rate().FindByCurrencies(r.Context(), req.FirstCurrency, req.SecondCurrency)
 		if rate != nil {
-			s.error(w, http.StatusConflict, fmt.Errorf("the exchange rate record for %s-%s already exists", req.FirstCurrency, req.SecondCurrency))
+			s.error(w, r, newAPIError(codeRateAlreadyExists, fmt.Sprintf("the exchange rate record for %s-%s already exists", req.FirstCurrency, req.SecondCurrency)))
 			return
 		}
 
-		res, err := getExchangeRates(s.config.CurrencyAPIKey, req.FirstCurrency)
+		res, err := s.provider.GetExchangeRates(r.Context(), req.FirstCurrency)
 		if err != nil {
-			s.error(w, http.StatusUnprocessableEntity, fmt.Errorf("error occurred while getting exchange rates for the currency %s: %s", req.FirstCurrency, err.Error()))
+			s.error(w, r, errUpstreamUnavailable.wrap(err))
 			return
 		}
 
-		exchangeRateValue, ok := res.Data[req.SecondCurrency]
+		quote, ok := res.Rates[req.SecondCurrency]
 		if !ok {
-			s.error(w, http.StatusUnprocessableEntity, fmt.Errorf("currency %s not found", req.SecondCurrency))
+			s.error(w, r, newAPIError(codeUnsupportedCurrency, fmt.Sprintf("the providers have no exchange rate of the currency %s", req.SecondCurrency), "second_currency"))
 			return
 		}
 
 		rate = &model.Rate{
-			FirstCurrency:  res.Query.BaseCurrency,
+			FirstCurrency:  res.BaseCurrency,
 			SecondCurrency: req.SecondCurrency,
-			Value:          exchangeRateValue,
+			Value:          quote.Value,
 			LastUpdateTime: time.Now(),
+			Source:         strings.Join(quote.Sources, ","),
 		}
 
-		if err = s.store.Rate().Create(rate); err != nil {
-			s.error(w, http.StatusInternalServerError, err)
+		// the rate may have been created by a concurrent request in the meantime,
+		// the violation of the unique index is responded to with 409 then
+		if err = s.store.Rate().Create(r.Context(), rate); err != nil {
+			s.error(w, r, err)
 			return
 		}
 
@@ -182,80 +265,874 @@ func (s *server) handleCreateRate() http.HandlerFunc {
 	}
 }
 
+type createRatesQuery struct {
+	BaseCurrency    string   `json:"base_currency" example:"USD"`
+	QuoteCurrencies []string `json:"quote_currencies" example:"RUB,EUR"`
+	All             bool     `json:"all" example:"false"` // the quote currencies are all the supported ones
+}
+
+type createRatesResponse struct {
+	Created     []*model.Rate `json:"created"`
+	Existing    []string      `json:"existing" example:"EUR"`    // quote currencies of the rates registered before
+	Unsupported []string      `json:"unsupported" example:"XYZ"` // quote currencies we or the providers don't support
+}
+
+// handleCreateRates godoc
+// @Summary      Create exchange rates of a base currency
+// @Description  create the records of the exchange rates between the base currency and each of the quote currencies
+// @Description  that isn't registered yet, all of them are taken from one response of the providers.
+// @Description  The base currency itself is skipped if it's among the quote ones.
+// @Tags         rate
+// @Accept       json
+// @Produce      json
+// @Param        input  body      createRatesQuery     true  "The base currency and the quote ones"
+// @Success      200    {object}  createRatesResponse  "Nothing was created"
+// @Success      201    {object}  createRatesResponse  "Created"
+// @Failure      400    {object}  problem              "Missing parameters or invalid payload"
+// @Failure      422    {object}  problem              "Invalid parameters or unsupported base currency"
+// @Failure      500    {object}  problem
+// @Failure      503    {object}  problem  "The exchange rate providers are unavailable"
+// @Router       /rates [post]
+func (s *server) handleCreateRates() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &createRatesQuery{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, errInvalidPayload.wrap(err))
+			return
+		}
+
+		if req.BaseCurrency == "" || (len(req.QuoteCurrencies) == 0 && !req.All) {
+			s.error(w, r, errMissingRequiredParams)
+			return
+		}
+
+		if len(req.QuoteCurrencies) > 0 && req.All {
+			s.error(w, r, errAmbiguousQuotes)
+			return
+		}
+
+		base := strings.ToUpper(req.BaseCurrency)
+		if err := checkCurrencies(map[string]string{"base_currency": base}); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		quotes := req.QuoteCurrencies
+		if req.All {
+			quotes = currency.Codes()
+		}
+
+		res := &createRatesResponse{
+			Created:     make([]*model.Rate, 0),
+			Existing:    make([]string, 0),
+			Unsupported: make([]string, 0),
+		}
+
+		baseRates, _, err := s.store.Rate().List(r.Context(), &model.RateFilter{FirstCurrency: base})
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		existing := make(map[string]bool, len(baseRates))
+		for _, rate := range baseRates {
+			existing[rate.SecondCurrency] = true
+		}
+
+		var missing []string
+		seen := map[string]bool{base: true}
+		for _, quote := range quotes {
+			quote = strings.ToUpper(quote)
+			if seen[quote] {
+				continue
+			}
+			seen[quote] = true
+
+			if !currency.IsSupported(quote) {
+				res.Unsupported = append(res.Unsupported, quote)
+				continue
+			}
+
+			if existing[quote] {
+				res.Existing = append(res.Existing, quote)
+				continue
+			}
+
+			missing = append(missing, quote)
+		}
+
+		if len(missing) == 0 {
+			s.respond(w, http.StatusOK, res)
+			return
+		}
+
+		rates, err := s.provider.GetExchangeRates(r.Context(), base)
+		if err != nil {
+			s.error(w, r, errUpstreamUnavailable.wrap(err))
+			return
+		}
+
+		now := time.Now()
+		for _, quote := range missing {
+			q, ok := rates.Rates[quote]
+			if !ok {
+				res.Unsupported = append(res.Unsupported, quote)
+				continue
+			}
+
+			rate := &model.Rate{
+				FirstCurrency:  rates.BaseCurrency,
+				SecondCurrency: quote,
+				Value:          q.Value,
+				LastUpdateTime: now,
+				Source:         strings.Join(q.Sources, ","),
+			}
+
+			if err = s.store.Rate().Create(r.Context(), rate); err != nil {
+				if errors.Is(err, store.ErrDuplicate) {
+					res.Existing = append(res.Existing, quote)
+					continue
+				}
+
+				s.error(w, r, err)
+				return
+			}
+
+			res.Created = append(res.Created, rate)
+		}
+
+		if len(res.Created) == 0 {
+			s.respond(w, http.StatusOK, res)
+			return
+		}
+
+		s.respond(w, http.StatusCreated, res)
+	}
+}
+
+type listRatesResponse struct {
+	Rates  []*model.Rate `json:"rates"`
+	Total  int           `json:"total" example:"1"`
+	Limit  int           `json:"limit" example:"50"`
+	Offset int           `json:"offset" example:"0"`
+}
+
+// handleListRates godoc
+// @Summary      List exchange rates
+// @Description  get a page of the exchange rate records, optionally filtered by currencies
+// @Tags         rate
+// @Produce      json
+// @Param        currency         query     string             false  "The currency that is either the first or the second one of the exchange rate"
+// @Param        first_currency   query     string             false  "The first currency of the exchange rate"
+// @Param        second_currency  query     string             false  "The second currency of the exchange rate"
+// @Param        limit            query     int                false  "The max number of records on the page, 50 by default"
+// @Param        offset           query     int                false  "The number of records to skip"
+// @Success      200              {object}  listRatesResponse  "Ok"
+// @Failure      422              {object}  problem            "Invalid parameters"
+// @Failure      500              {object}  problem
+// @Router       /rates [get]
+func (s *server) handleListRates() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		filter := &model.RateFilter{
+			Currency:       strings.ToUpper(q.Get("currency")),
+			FirstCurrency:  strings.ToUpper(q.Get("first_currency")),
+			SecondCurrency: strings.ToUpper(q.Get("second_currency")),
+			Limit:          defaultRatesLimit,
+		}
+
+		var err error
+		if q.Get("limit") != "" {
+			if filter.Limit, err = strconv.Atoi(q.Get("limit")); err != nil || filter.Limit < 1 || filter.Limit > maxRatesLimit {
+				s.error(w, r, errWrongPaginationParams)
+				return
+			}
+		}
+
+		if q.Get("offset") != "" {
+			if filter.Offset, err = strconv.Atoi(q.Get("offset")); err != nil || filter.Offset < 0 {
+				s.error(w, r, errWrongPaginationParams)
+				return
+			}
+		}
+
+		res := &listRatesResponse{
+			Limit:  filter.Limit,
+			Offset: filter.Offset,
+		}
+
+		if res.Rates, res.Total, err = s.store.Rate().List(r.Context(), filter); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, http.StatusOK, res)
+	}
+}
+
+// handleGetRate godoc
+// @Summary      Get an exchange rate
+// @Description  get the exchange rate record by its ID
+// @Tags         rate
+// @Produce      json
+// @Param        id   path      int         true  "The ID of the exchange rate record"
+// @Success      200  {object}  model.Rate  "Ok"
+// @Failure      400  {object}  problem     "Invalid ID"
+// @Failure      404  {object}  problem     "There is no record of the exchange rate"
+// @Failure      500  {object}  problem
+// @Router       /rate/{id} [get]
+func (s *server) handleGetRate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			s.error(w, r, errWrongIDParam)
+			return
+		}
+
+		rate, err := s.store.Rate().Find(r.Context(), id)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, http.StatusOK, rate)
+	}
+}
+
+type updateRateQuery struct {
+	Value       decimal.Decimal `json:"value" swaggertype:"string" example:"75.4"`
+	PinnedUntil *time.Time      `json:"pinned_until,omitempty" example:"2019-11-10T21:21:46+00:00"`
+	Author      string          `json:"author" example:"j.doe"`
+	Reason      string          `json:"reason" example:"fixed by the treasury for the end of the quarter"`
+}
+
+// handleUpdateRate godoc
+// @Summary      Set an exchange rate manually
+// @Description  override the value of the exchange rate record, the record gets the "manual" source.
+// @Description  If pinned_until is set, the rate isn't updated automatically until then, author and reason are required in this case.
+// @Description  Every change is recorded in the audit trail of the rate.
+// @Tags         rate
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int              true  "The ID of the exchange rate record"
+// @Param        input  body      updateRateQuery  true  "A new value of the exchange rate"
+// @Success      200    {object}  model.Rate       "Ok"
+// @Failure      400    {object}  problem          "Invalid ID, missing parameters or invalid payload"
+// @Failure      404    {object}  problem          "There is no record of the exchange rate"
+// @Failure      422    {object}  problem          "Invalid parameters"
+// @Failure      500    {object}  problem
+// @Router       /rate/{id} [put]
+func (s *server) handleUpdateRate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			s.error(w, r, errWrongIDParam)
+			return
+		}
+
+		req := &updateRateQuery{}
+		if err = json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, errInvalidPayload.wrap(err))
+			return
+		}
+
+		if req.Value.IsZero() {
+			s.error(w, r, errMissingRequiredParams)
+			return
+		}
+
+		if req.PinnedUntil != nil {
+			if req.Author == "" || req.Reason == "" {
+				s.error(w, r, errMissingPinAuthor)
+				return
+			}
+
+			if !req.PinnedUntil.After(time.Now()) {
+				s.error(w, r, errWrongPinnedUntil)
+				return
+			}
+		}
+
+		rate, err := s.store.Rate().Find(r.Context(), id)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		rateUpd := &model.Rate{
+			ID:             rate.ID,
+			FirstCurrency:  rate.FirstCurrency,
+			SecondCurrency: rate.SecondCurrency,
+			Value:          req.Value,
+			LastUpdateTime: time.Now(),
+			Source:         manualRateSource,
+			PinnedUntil:    req.PinnedUntil,
+		}
+
+		if err = rateUpd.Validate(); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		// the change isn't saved without its audit entry
+		if err = s.store.Rate().UpdateWithAudit(r.Context(), rateUpd, &model.RateAuditEntry{
+			RateID:         rateUpd.ID,
+			FirstCurrency:  rateUpd.FirstCurrency,
+			SecondCurrency: rateUpd.SecondCurrency,
+			Value:          rateUpd.Value,
+			PinnedUntil:    rateUpd.PinnedUntil,
+			Author:         req.Author,
+			Reason:         req.Reason,
+			CreatedAt:      rateUpd.LastUpdateTime,
+		}); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, http.StatusOK, rateUpd)
+	}
+}
+
+// handleGetRateAudit godoc
+// @Summary      Exchange rate audit trail
+// @Description  get the manual changes of the exchange rate: who made them, when and why
+// @Tags         rate
+// @Produce      json
+// @Param        id   path      int                   true  "The ID of the exchange rate record"
+// @Success      200  {array}   model.RateAuditEntry  "Ok"
+// @Failure      400  {object}  problem               "Invalid ID"
+// @Failure      500  {object}  problem
+// @Router       /rate/{id}/audit [get]
+func (s *server) handleGetRateAudit() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			s.error(w, r, errWrongIDParam)
+			return
+		}
+
+		entries, err := s.store.RateAudit().FindByRate(r.Context(), id)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		s.respond(w, http.StatusOK, entries)
+	}
+}
+
+// handleDeleteRate godoc
+// @Summary      Delete an exchange rate
+// @Description  stop tracking the exchange rate, its history is kept
+// @Tags         rate
+// @Param        id   path  int  true  "The ID of the exchange rate record"
+// @Success      204  "No Content"
+// @Failure      400  {object}  problem  "Invalid ID"
+// @Failure      404  {object}  problem  "There is no record of the exchange rate"
+// @Failure      500  {object}  problem
+// @Router       /rate/{id} [delete]
+func (s *server) handleDeleteRate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			s.error(w, r, errWrongIDParam)
+			return
+		}
+
+		if err = s.store.Rate().Delete(r.Context(), id); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+type rateHistoryResponse struct {
+	FirstCurrency  string             `json:"first_currency" example:"USD"`
+	SecondCurrency string             `json:"second_currency" example:"RUB"`
+	Start          time.Time          `json:"start" example:"2019-11-09T00:00:00Z"`
+	End            time.Time          `json:"end" example:"2019-11-10T00:00:00Z"`
+	Interval       string             `json:"interval,omitempty" example:"hour"` // omitted for the raw points
+	Points         []*model.RatePoint `json:"points"`
+}
+
+// handleGetRateHistory godoc
+// @Summary      Exchange rate history
+// @Description  get the values the exchange rate had over a period of time, optionally downsampled to the last value of every hour or day
+// @Tags         rate
+// @Produce      json
+// @Param        from      path      string               true   "The first currency of the exchange rate"
+// @Param        to        path      string               true   "The second currency of the exchange rate"
+// @Param        start     query     string               false  "The start of the period in RFC 3339 format, 24 hours before the end by default"
+// @Param        end       query     string               false  "The end of the period in RFC 3339 format, the current time by default"
+// @Param        interval  query     string               false  "The downsampling interval"  Enums(raw, hour, day)
+// @Success      200       {object}  rateHistoryResponse  "Ok"
+// @Failure      422       {object}  problem              "Invalid parameters or unsupported currencies"
+// @Failure      500       {object}  problem
+// @Router       /rate/{from}/{to}/history [get]
+func (s *server) handleGetRateHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		q := r.URL.Query()
+
+		from, to := strings.ToUpper(vars["from"]), strings.ToUpper(vars["to"])
+		if err := checkCurrencies(map[string]string{
+			"from": from,
+			"to":   to,
+		}); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		end := time.Now()
+		if q.Get("end") != "" {
+			t, err := time.Parse(time.RFC3339, q.Get("end"))
+			if err != nil {
+				s.error(w, r, errWrongTimeRange)
+				return
+			}
+			end = t
+		}
+
+		start := end.Add(-defaultHistoryPeriod)
+		if q.Get("start") != "" {
+			t, err := time.Parse(time.RFC3339, q.Get("start"))
+			if err != nil {
+				s.error(w, r, errWrongTimeRange)
+				return
+			}
+			start = t
+		}
+
+		if start.After(end) {
+			s.error(w, r, errWrongTimeRange)
+			return
+		}
+
+		interval, ok := model.ParseHistoryInterval(q.Get("interval"))
+		if !ok {
+			s.error(w, r, errWrongIntervalParam)
+			return
+		}
+
+		res := &rateHistoryResponse{
+			FirstCurrency:  from,
+			SecondCurrency: to,
+			Start:          start,
+			End:            end,
+			Interval:       string(interval),
+		}
+
+		points, err := s.store.RateHistory().FindRange(r.Context(), res.FirstCurrency, res.SecondCurrency, start, end, interval)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+		res.Points = points
+
+		s.respond(w, http.StatusOK, res)
+	}
+}
+
 type convertCurrencyQuery struct {
-	CurrencyFrom string  `json:"currency_from" example:"RUB"`
-	CurrencyTo   string  `json:"currency_to" example:"RUB"`
-	Value        float32 `json:"value,string" example:"123.321"`
+	CurrencyFrom string          `json:"currency_from" example:"RUB"`
+	CurrencyTo   string          `json:"currency_to" example:"RUB"`
+	Value        decimal.Decimal `json:"value" swaggertype:"string" example:"123.321"`
+	At           *time.Time      `json:"at,omitempty" example:"2019-11-09T21:21:46+00:00"`
+	Rounding     string          `json:"rounding" example:"half_even"`
+	MaxAge       *int            `json:"max_age,omitempty" example:"3600"`
+	OnStale      string          `json:"on_stale" example:"flag"`
 }
 
 type convertCurrencyResponse struct {
 	Query            convertCurrencyQuery `json:"query"`
-	ConversionResult float32              `json:"conversion_result,string" example:"123.321"`
-	LastUpdateTime   time.Time            `json:"last_update_time" example:"2019-11-09T21:21:46+00:00"`
+	ConversionResult decimal.Decimal      `json:"conversion_result" swaggertype:"string" example:"123.321"`
+	RoundedResult    decimal.Decimal      `json:"rounded_result" swaggertype:"string" example:"123.32"` // to the minor unit of the target currency
+	Rate             decimal.Decimal      `json:"rate" swaggertype:"string" example:"0.0132"`
+	LastUpdateTime   time.Time            `json:"last_update_time" example:"2019-11-09T21:21:46+00:00"` // the oldest one of the rates used for the conversion
+	Inverse          bool                 `json:"inverse" example:"false"`                              // the rate of the reverse pair was inverted for at least one leg
+	Stale            bool                 `json:"stale" example:"false"`                                // a rate used for the conversion is older than the max age
+	Path             []string             `json:"path" example:"RUB,USD"`
+	Legs             []*conversionLeg     `json:"legs"`
 }
 
 // handleConvertCurrency godoc
 // @Summary      Currency conversion
-// @Description  convert the value from one currency to another according to the exchange rate
+// @Description  convert the value from one currency to another according to the exchange rate, the rate of the reverse pair is inverted if the direct one isn't registered, if neither is, the conversion goes through a chain of the registered pairs
 // @Tags         other
 // @Accept       json
 // @Produce      json
-// @Param        currency_from  query     string                   true  "The currency whose value will be converted to another currency"
-// @Param        currency_to    query     string                   true  "The currency to which the value from the first currency will be converted"
-// @Param        value          query     number                   true  "The value that will be converted from one currency to another"
+// @Param        currency_from  query     string                   true   "The currency whose value will be converted to another currency"
+// @Param        currency_to    query     string                   true   "The currency to which the value from the first currency will be converted"
+// @Param        value          query     number                   true   "The value that will be converted from one currency to another"
+// @Param        at             query     string                   false  "Convert at the rate that was in effect at this RFC 3339 timestamp"
+// @Param        date           query     string                   false  "Convert at the rate that was in effect at the end of this YYYY-MM-DD date (UTC)"
+// @Param        rounding       query     string                   false  "The mode of rounding the result to the minor unit of the target currency, half_even by default"  Enums(half_even, half_up, down, up)
+// @Param        max_age        query     int                      false  "The max age of the rates in seconds, overrides the configured one, 0 means no limit"
+// @Param        on_stale       query     string                   false  "Whether to flag the conversion at a rate older than the max age as stale or reject it, the configured policy by default"  Enums(flag, reject)
 // @Success      200            {object}  convertCurrencyResponse  "Ok"
-// @Failure      400            {object}  errorResponse            "Missing parameters"
-// @Failure      404            {object}  errorResponse            "There is no record of the exchange rate"
-// @Failure      422            {object}  errorResponse            "Invalid parameters"
-// @Failure      500            {object}  errorResponse
+// @Failure      400            {object}  problem                  "Missing parameters"
+// @Failure      404            {object}  problem                  "There is no record of the exchange rate"
+// @Failure      422            {object}  problem                  "Invalid parameters or unsupported currencies"
+// @Failure      500            {object}  problem
+// @Failure      503            {object}  problem  "The exchange rate is stale"
 // @Router       /convert [get]
 func (s *server) handleConvertCurrency() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		q := r.URL.Query()
 		if !(q.Has("currency_from") && q.Has("currency_to") && q.Has("value")) {
-			s.error(w, http.StatusBadRequest, errMissingRequiredParams)
+			s.error(w, r, errMissingRequiredParams)
 			return
 		}
 
-		valueFloat64, err := strconv.ParseFloat(q.Get("value"), 32)
+		from, to := strings.ToUpper(q.Get("currency_from")), strings.ToUpper(q.Get("currency_to"))
+		if err := checkCurrencies(map[string]string{
+			"currency_from": from,
+			"currency_to":   to,
+		}); err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		value, err := decimal.NewFromString(q.Get("value"))
 		if err != nil {
-			s.error(w, http.StatusUnprocessableEntity, errWrongValueParam)
+			s.error(w, r, errWrongValueParam)
 			return
 		}
 
-		req := &convertCurrencyQuery{
-			CurrencyFrom: q.Get("currency_from"),
-			CurrencyTo:   q.Get("currency_to"),
-			Value:        float32(valueFloat64),
+		req, err := s.parseConvertOptions(q)
+		if err != nil {
+			s.error(w, r, err)
+			return
 		}
+		req.CurrencyFrom = from
+		req.CurrencyTo = to
+		req.Value = value
 
-		rate, err := s.store.Rate().FindByCurrencies(req.CurrencyFrom, req.CurrencyTo)
+		rate, err := s.converter.findRate(r.Context(), req.CurrencyFrom, req.CurrencyTo, req.At)
 		if err != nil {
-			if err == store.ErrRowNotFound {
-				s.error(w, http.StatusNotFound, err)
-				return
-			}
+			s.error(w, r, err)
+			return
+		}
+
+		res, err := s.convert(req, rate)
+		if err != nil {
+			s.error(w, r, err)
+			return
+		}
+
+		if res.Stale {
+			w.Header().Set("Warning", staleWarning)
+		}
+
+		s.respond(w, http.StatusOK, res)
+	}
+}
+
+type convertBatchItem struct {
+	ID           string           `json:"id" example:"invoice-42-line-1"`
+	CurrencyFrom string           `json:"currency_from" example:"RUB"`
+	CurrencyTo   string           `json:"currency_to" example:"USD"`
+	Value        *decimal.Decimal `json:"value" swaggertype:"string" example:"123.321"`
+}
+
+// convertBatchResult is either the result of the conversion of a batch item or the reason it failed for.
+type convertBatchResult struct {
+	ID     string                   `json:"id" example:"invoice-42-line-1"`
+	Result *convertCurrencyResponse `json:"result,omitempty"`
+	Error  *problem                 `json:"error,omitempty"`
+}
+
+type convertBatchResponse struct {
+	Results []*convertBatchResult `json:"results"`
+	Failed  int                   `json:"failed" example:"0"` // number of the items that weren't converted
+}
+
+// handleConvertBatch godoc
+// @Summary      Batch currency conversion
+// @Description  convert the values of the items like GET /convert does, the rate of every distinct pair is found once.
+// @Description  The items are converted independently, each of them gets either a result or an error, in the order they were given,
+// @Description  a malformed item included.
+// @Tags         other
+// @Accept       json
+// @Produce      json
+// @Param        input     body      []convertBatchItem    true   "The items to convert"
+// @Param        at        query     string                false  "Convert at the rates that were in effect at this RFC 3339 timestamp"
+// @Param        date      query     string                false  "Convert at the rates that were in effect at the end of this YYYY-MM-DD date (UTC)"
+// @Param        rounding  query     string                false  "The mode of rounding the results to the minor unit of the target currencies, half_even by default"  Enums(half_even, half_up, down, up)
+// @Param        max_age   query     int                   false  "The max age of the rates in seconds, overrides the configured one, 0 means no limit"
+// @Param        on_stale  query     string                false  "Whether to flag the conversions at rates older than the max age as stale or reject them, the configured policy by default"  Enums(flag, reject)
+// @Success      200       {object}  convertBatchResponse  "Ok"
+// @Failure      400       {object}  problem               "The payload isn't an array"
+// @Failure      422       {object}  problem               "Invalid parameters or too many items"
+// @Router       /convert/batch [post]
+func (s *server) handleConvertBatch() http.HandlerFunc {
+	type pair struct {
+		currencyFrom string
+		currencyTo   string
+	}
 
-			s.error(w, http.StatusInternalServerError, err)
+	type pairRate struct {
+		rate *conversionRate
+		err  error
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		opts, err := s.parseConvertOptions(r.URL.Query())
+		if err != nil {
+			s.error(w, r, err)
 			return
 		}
 
-		res := &convertCurrencyResponse{
-			Query:            *req,
-			ConversionResult: req.Value * rate.Value,
-			LastUpdateTime:   rate.LastUpdateTime,
+		// the items are decoded one by one, so that a malformed item fails alone
+		var items []json.RawMessage
+		if err = json.NewDecoder(r.Body).Decode(&items); err != nil {
+			s.error(w, r, errInvalidPayload.wrap(err))
+			return
+		}
+
+		if len(items) == 0 || len(items) > maxBatchItems {
+			s.error(w, r, errWrongBatchSize)
+			return
+		}
+
+		rates := make(map[pair]*pairRate)
+
+		res := &convertBatchResponse{
+			Results: make([]*convertBatchResult, 0, len(items)),
+		}
+
+		for _, raw := range items {
+			item := &convertBatchItem{}
+			if err = json.Unmarshal(raw, item); err != nil {
+				// the ID is reported even if the other fields are malformed
+				var identified struct {
+					ID string `json:"id"`
+				}
+				_ = json.Unmarshal(raw, &identified)
+
+				res.Results = append(res.Results, &convertBatchResult{ID: identified.ID, Error: s.problem(r, errInvalidPayload.wrap(err))})
+				continue
+			}
+			item.CurrencyFrom = strings.ToUpper(item.CurrencyFrom)
+			item.CurrencyTo = strings.ToUpper(item.CurrencyTo)
+
+			result := &convertBatchResult{ID: item.ID}
+			res.Results = append(res.Results, result)
+
+			if item.CurrencyFrom == "" || item.CurrencyTo == "" || item.Value == nil {
+				result.Error = s.problem(r, errMissingRequiredParams)
+				continue
+			}
+
+			if err = checkCurrencies(map[string]string{
+				"currency_from": item.CurrencyFrom,
+				"currency_to":   item.CurrencyTo,
+			}); err != nil {
+				result.Error = s.problem(r, err)
+				continue
+			}
+
+			p := pair{item.CurrencyFrom, item.CurrencyTo}
+			rate, ok := rates[p]
+			if !ok {
+				rate = &pairRate{}
+				rate.rate, rate.err = s.converter.findRate(r.Context(), p.currencyFrom, p.currencyTo, opts.At)
+				rates[p] = rate
+			}
+
+			if rate.err != nil {
+				result.Error = s.problem(r, rate.err)
+				continue
+			}
+
+			req := *opts
+			req.CurrencyFrom = item.CurrencyFrom
+			req.CurrencyTo = item.CurrencyTo
+			req.Value = *item.Value
+
+			if result.Result, err = s.convert(&req, rate.rate); err != nil {
+				result.Error = s.problem(r, err)
+			}
+		}
+
+		var stale bool
+		for _, result := range res.Results {
+			if result.Error != nil {
+				res.Failed++
+			} else if result.Result.Stale {
+				stale = true
+			}
+		}
+
+		if stale {
+			w.Header().Set("Warning", staleWarning)
 		}
 
 		s.respond(w, http.StatusOK, res)
 	}
 }
 
-type errorResponse struct {
-	Message string `json:"message"`
+// parseConvertOptions returns the query of a conversion with the options set by the query parameters,
+// the currencies and the value aren't set.
+func (s *server) parseConvertOptions(q url.Values) (*convertCurrencyQuery, error) {
+	at, err := parseAtParams(q.Get("at"), q.Get("date"))
+	if err != nil {
+		return nil, err
+	}
+
+	rounding, ok := currency.ParseRoundingMode(q.Get("rounding"))
+	if !ok {
+		return nil, errWrongRoundingParam
+	}
+
+	var maxAge *int
+	if q.Has("max_age") {
+		seconds, err := strconv.Atoi(q.Get("max_age"))
+		if err != nil || seconds < 0 {
+			return nil, errWrongMaxAgeParam
+		}
+		maxAge = &seconds
+	}
+
+	onStale := s.staleness.policy
+	if q.Has("on_stale") {
+		if onStale, ok = parseStalePolicy(q.Get("on_stale")); !ok {
+			return nil, errWrongOnStaleParam
+		}
+	}
+
+	return &convertCurrencyQuery{
+		At:       at,
+		Rounding: string(rounding),
+		MaxAge:   maxAge,
+		OnStale:  string(onStale),
+	}, nil
 }
 
-func (s *server) error(w http.ResponseWriter, statusCode int, err error) {
-	s.respond(w, statusCode, errorResponse{err.Error()})
+// convert converts the value of the query at the rate, the conversion at a stale rate is either flagged
+// or rejected with errStaleRate according to the query.
+func (s *server) convert(req *convertCurrencyQuery, rate *conversionRate) (*convertCurrencyResponse, error) {
+	// the rates of the past conversions are the ones that were in effect, so they can't be stale
+	var stale bool
+	if req.At == nil {
+		var maxAge *time.Duration
+		if req.MaxAge != nil {
+			d := time.Second * time.Duration(*req.MaxAge)
+			maxAge = &d
+		}
+		stale = s.staleness.isStale(rate.Legs, maxAge, time.Now())
+	}
+
+	if stale && stalePolicy(req.OnStale) == stalePolicyReject {
+		return nil, errStaleRate
+	}
+
+	result := req.Value.Mul(rate.Value)
+
+	path := []string{req.CurrencyFrom}
+	for _, leg := range rate.Legs {
+		path = append(path, leg.CurrencyTo)
+	}
+
+	return &convertCurrencyResponse{
+		Query:            *req,
+		ConversionResult: result,
+		RoundedResult:    currency.Round(result, req.CurrencyTo, currency.RoundingMode(req.Rounding)),
+		Rate:             rate.Value,
+		LastUpdateTime:   rate.LastUpdateTime,
+		Inverse:          rate.Inverse,
+		Stale:            stale,
+		Path:             path,
+		Legs:             rate.Legs,
+	}, nil
+}
+
+// checkCurrencies returns an UNSUPPORTED_CURRENCY error if any of the currencies, given by the names of
+// the parameters they're specified in, isn't supported. The codes should be upper-cased already.
+func checkCurrencies(params map[string]string) error {
+	var unsupported *apiError
+	for param, code := range params {
+		if currency.IsSupported(code) {
+			continue
+		}
+
+		if unsupported == nil {
+			unsupported = newAPIError(codeUnsupportedCurrency, "one or more currencies aren't supported")
+			unsupported.fields = make(map[string]string)
+		}
+		unsupported.fields[param] = fmt.Sprintf("currency %s isn't supported", code)
+	}
+
+	if unsupported == nil {
+		return nil
+	}
+
+	return unsupported
+}
+
+// parseAtParams returns the moment of time a conversion should be made at,
+// nil means the current rate should be used.
+func parseAtParams(at, date string) (*time.Time, error) {
+	switch {
+	case at != "" && date != "":
+		return nil, errWrongAtParam
+	case at != "":
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return nil, errWrongAtParam
+		}
+		return &t, nil
+	case date != "":
+		t, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil, errWrongAtParam
+		}
+		t = t.Add(24*time.Hour - time.Nanosecond)
+		return &t, nil
+	default:
+		return nil, nil
+	}
+}
+
+// handleGetProviders godoc
+// @Summary      Rate providers status
+// @Description  get the state of the circuit breakers of the upstream exchange rate providers
+// @Tags         provider
+// @Produce      json
+// @Success      200  {array}  provider.Status  "Ok"
+// @Router       /providers [get]
+func (s *server) handleGetProviders() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		statuses := make([]provider.Status, 0)
+		if reporter, ok := s.provider.(provider.StatusReporter); ok {
+			statuses = append(statuses, reporter.Statuses()...)
+		}
+
+		s.respond(w, http.StatusOK, statuses)
+	}
+}
+
+// handleGetCacheStats godoc
+// @Summary      Rate cache statistics
+// @Description  get the number of the exchange rate lookups served from the cache and the ones that went to the database
+// @Tags         other
+// @Produce      json
+// @Success      200  {object}  cachestore.Stats  "Ok"
+// @Failure      404  {object}  problem           "The cache is disabled"
+// @Router       /cache/stats [get]
+func (s *server) handleGetCacheStats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reporter, ok := s.store.Rate().(cachestore.StatsReporter)
+		if !ok {
+			s.error(w, r, errCacheDisabled)
+			return
+		}
+
+		s.respond(w, http.StatusOK, reporter.Stats())
+	}
 }
 
 func (s *server) respond(w http.ResponseWriter, statusCode int, data interface{}) {
@@ -2,17 +2,28 @@ package apiserver
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/currency"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider/testprovider"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/cachestore"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/teststore"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestServer_HandleCreateRate(t *testing.T) {
-	srv := newServer(TestConfig(t), teststore.New(), TestLogger(t))
+	srv := newServer(TestConfig(t), teststore.New(), testprovider.New(), TestLogger(t))
 
 	testCases := []struct {
 		name         string
@@ -43,6 +54,30 @@ func TestServer_HandleCreateRate(t *testing.T) {
 			},
 			expectedCode: http.StatusUnprocessableEntity,
 		},
+		{
+			name: "identical currencies in different cases",
+			payload: map[string]string{
+				"first_currency":  "eur",
+				"second_currency": "EUR",
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "lowercase currencies",
+			payload: map[string]string{
+				"first_currency":  "eur",
+				"second_currency": "Rub",
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name: "already exists in another case",
+			payload: map[string]string{
+				"first_currency":  "Eur",
+				"second_currency": "rub",
+			},
+			expectedCode: http.StatusConflict,
+		},
 		{
 			name:         "invalid payload",
 			payload:      "invalid",
@@ -72,11 +107,68 @@ func TestServer_HandleCreateRate(t *testing.T) {
 	}
 }
 
+// racingStore misses the stored rates in the lookups by currencies and the lists, as if they were created by concurrent requests.
+type racingStore struct {
+	*teststore.Store
+}
+
+func (s racingStore) Rate() store.RateRepository {
+	return racingRateRepository{s.Store.Rate()}
+}
+
+type racingRateRepository struct {
+	store.RateRepository
+}
+
+func (r racingRateRepository) FindByCurrencies(context.Context, string, string) (*model.Rate, error) {
+	return nil, store.ErrRowNotFound
+}
+
+func (r racingRateRepository) List(context.Context, *model.RateFilter) ([]*model.Rate, int, error) {
+	return []*model.Rate{}, 0, nil
+}
+
+func TestServer_HandleCreateRate_Concurrent(t *testing.T) {
+	st := teststore.New()
+	srv := newServer(TestConfig(t), racingStore{st}, testprovider.New(), TestLogger(t))
+
+	for _, expectedCode := range []int{http.StatusCreated, http.StatusConflict} {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/rate", bytes.NewBufferString(`{"first_currency":"USD","second_currency":"RUB"}`))
+
+		srv.ServeHTTP(rec, req)
+		assert.Equal(t, expectedCode, rec.Code)
+	}
+
+	rates, err := st.Rate().FindAll(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, rates, 1)
+}
+
+func TestServer_HandleCreateRates_Concurrent(t *testing.T) {
+	st := teststore.New()
+	_ = st.Rate().Create(context.Background(), model.TestRate(t))
+	srv := newServer(TestConfig(t), racingStore{st}, testprovider.New(), TestLogger(t))
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/rates", bytes.NewBufferString(`{"base_currency":"USD","quote_currencies":["RUB","EUR"]}`))
+
+	srv.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	res := &createRatesResponse{}
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(res))
+	assert.Equal(t, []string{"RUB"}, res.Existing)
+	if assert.Len(t, res.Created, 1) {
+		assert.Equal(t, "EUR", res.Created[0].SecondCurrency)
+	}
+}
+
 func TestServer_HandleConvertCurrency(t *testing.T) {
-	srv := newServer(TestConfig(t), teststore.New(), TestLogger(t))
+	srv := newServer(TestConfig(t), teststore.New(), testprovider.New(), TestLogger(t))
 
 	r := model.TestRate(t)
-	_ = srv.store.Rate().Create(r)
+	_ = srv.store.Rate().Create(context.Background(), r)
 
 	testCases := []struct {
 		name         string
@@ -104,7 +196,7 @@ func TestServer_HandleConvertCurrency(t *testing.T) {
 				"currency_to":   "ruble",
 				"value":         "10",
 			},
-			expectedCode: http.StatusNotFound,
+			expectedCode: http.StatusUnprocessableEntity,
 		},
 		{
 			name: "valid",
@@ -115,6 +207,75 @@ func TestServer_HandleConvertCurrency(t *testing.T) {
 			},
 			expectedCode: http.StatusOK,
 		},
+		{
+			name: "lowercase currencies",
+			payload: map[string]string{
+				"currency_from": "usd",
+				"currency_to":   "rub",
+				"value":         "10",
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "mixed case currencies",
+			payload: map[string]string{
+				"currency_from": "Usd",
+				"currency_to":   "rUB",
+				"value":         "10",
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "valid at",
+			payload: map[string]string{
+				"currency_from": r.FirstCurrency,
+				"currency_to":   r.SecondCurrency,
+				"value":         "10",
+				"at":            time.Now().Add(time.Hour).Format(time.RFC3339),
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "valid date",
+			payload: map[string]string{
+				"currency_from": r.FirstCurrency,
+				"currency_to":   r.SecondCurrency,
+				"value":         "10",
+				"date":          time.Now().UTC().Format("2006-01-02"),
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "at before the first rate",
+			payload: map[string]string{
+				"currency_from": r.FirstCurrency,
+				"currency_to":   r.SecondCurrency,
+				"value":         "10",
+				"at":            time.Now().Add(-time.Hour).Format(time.RFC3339),
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "invalid at",
+			payload: map[string]string{
+				"currency_from": r.FirstCurrency,
+				"currency_to":   r.SecondCurrency,
+				"value":         "10",
+				"at":            "yesterday",
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "both at and date",
+			payload: map[string]string{
+				"currency_from": r.FirstCurrency,
+				"currency_to":   r.SecondCurrency,
+				"value":         "10",
+				"at":            time.Now().Format(time.RFC3339),
+				"date":          time.Now().UTC().Format("2006-01-02"),
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -133,3 +294,982 @@ func TestServer_HandleConvertCurrency(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_HandleConvertCurrency_Precision(t *testing.T) {
+	srv := newServer(TestConfig(t), teststore.New(), testprovider.New(), TestLogger(t))
+
+	r := model.TestRate(t)
+	_ = srv.store.Rate().Create(context.Background(), r)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/convert?currency_from=USD&currency_to=RUB&value=12345678901234.56", nil)
+
+	srv.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	res := &convertCurrencyResponse{}
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(res))
+	assert.Equal(t, "1498888875398887.9296", res.ConversionResult.String())
+	assert.Equal(t, "1498888875398887.93", res.RoundedResult.String())
+}
+
+func TestServer_HandleConvertCurrency_Inverse(t *testing.T) {
+	srv := newServer(TestConfig(t), teststore.New(), testprovider.New(), TestLogger(t))
+
+	r := model.TestRate(t)
+	_ = srv.store.Rate().Create(context.Background(), r)
+
+	testCases := []struct {
+		name            string
+		query           string
+		expectedInverse bool
+		expectedRounded string
+	}{
+		{
+			name:            "direct",
+			query:           "currency_from=USD&currency_to=RUB&value=2",
+			expectedInverse: false,
+			expectedRounded: "242.82",
+		},
+		{
+			name:            "inverse",
+			query:           "currency_from=RUB&currency_to=USD&value=242.82",
+			expectedInverse: true,
+			expectedRounded: "2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/convert?"+tc.query, nil)
+
+			srv.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			res := &convertCurrencyResponse{}
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(res))
+			assert.Equal(t, tc.expectedInverse, res.Inverse)
+			assert.Equal(t, tc.expectedRounded, res.RoundedResult.String())
+		})
+	}
+}
+
+func TestServer_HandleConvertCurrency_CrossRate(t *testing.T) {
+	st := teststore.New()
+	for _, r := range []*model.Rate{
+		{FirstCurrency: "USD", SecondCurrency: "RUB", Value: decimal.RequireFromString("75"), LastUpdateTime: time.Now()},
+		{FirstCurrency: "EUR", SecondCurrency: "USD", Value: decimal.RequireFromString("1.25"), LastUpdateTime: time.Now().Add(-time.Hour)},
+		{FirstCurrency: "EUR", SecondCurrency: "GBP", Value: decimal.RequireFromString("0.8"), LastUpdateTime: time.Now()},
+	} {
+		_ = st.Rate().Create(context.Background(), r)
+	}
+
+	testCases := []struct {
+		name            string
+		maxHops         int
+		query           string
+		expectedCode    int
+		expectedPath    []string
+		expectedRounded string
+	}{
+		{
+			name:            "two legs",
+			maxHops:         3,
+			query:           "currency_from=RUB&currency_to=EUR&value=750",
+			expectedCode:    http.StatusOK,
+			expectedPath:    []string{"RUB", "USD", "EUR"},
+			expectedRounded: "8",
+		},
+		{
+			name:            "three legs",
+			maxHops:         3,
+			query:           "currency_from=RUB&currency_to=GBP&value=750",
+			expectedCode:    http.StatusOK,
+			expectedPath:    []string{"RUB", "USD", "EUR", "GBP"},
+			expectedRounded: "6.4",
+		},
+		{
+			name:         "too many hops",
+			maxHops:      2,
+			query:        "currency_from=RUB&currency_to=GBP&value=750",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "no path",
+			maxHops:      3,
+			query:        "currency_from=RUB&currency_to=JPY&value=750",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := TestConfig(t)
+			cfg.MaxConversionHops = tc.maxHops
+			srv := newServer(cfg, st, testprovider.New(), TestLogger(t))
+
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/convert?"+tc.query, nil)
+
+			srv.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+
+			if tc.expectedCode == http.StatusOK {
+				res := &convertCurrencyResponse{}
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(res))
+				assert.Equal(t, tc.expectedPath, res.Path)
+				assert.Equal(t, len(tc.expectedPath)-1, len(res.Legs))
+				assert.True(t, res.Inverse)
+				assert.Equal(t, tc.expectedRounded, res.RoundedResult.String())
+				assert.True(t, res.LastUpdateTime.Before(time.Now().Add(-time.Minute)))
+			}
+		})
+	}
+}
+
+// historyCountingStore counts the rates looked up in the history.
+type historyCountingStore struct {
+	*teststore.Store
+	lookups *int
+}
+
+func (s historyCountingStore) RateHistory() store.RateHistoryRepository {
+	return historyCountingRepository{s.Store.RateHistory(), s.lookups}
+}
+
+type historyCountingRepository struct {
+	store.RateHistoryRepository
+	lookups *int
+}
+
+func (r historyCountingRepository) FindAsOf(ctx context.Context, firstCurrency, secondCurrency string, t time.Time) (*model.RatePoint, error) {
+	*r.lookups++
+	return r.RateHistoryRepository.FindAsOf(ctx, firstCurrency, secondCurrency, t)
+}
+
+func TestServer_HandleConvertCurrency_CrossRateAt(t *testing.T) {
+	st := historyCountingStore{teststore.New(), new(int)}
+	for _, r := range []*model.Rate{
+		{FirstCurrency: "RUB", SecondCurrency: "GBP", Value: decimal.RequireFromString("0.01"), LastUpdateTime: time.Now()},
+		{FirstCurrency: "GBP", SecondCurrency: "EUR", Value: decimal.RequireFromString("1.2"), LastUpdateTime: time.Now()},
+		{FirstCurrency: "USD", SecondCurrency: "RUB", Value: decimal.RequireFromString("75"), LastUpdateTime: time.Now().Add(-2 * time.Hour)},
+		{FirstCurrency: "USD", SecondCurrency: "JPY", Value: decimal.RequireFromString("150"), LastUpdateTime: time.Now().Add(-2 * time.Hour)},
+		{FirstCurrency: "EUR", SecondCurrency: "JPY", Value: decimal.RequireFromString("150"), LastUpdateTime: time.Now().Add(-2 * time.Hour)},
+	} {
+		_ = st.Rate().Create(context.Background(), r)
+	}
+
+	cfg := TestConfig(t)
+	cfg.MaxConversionHops = 3
+	srv := newServer(cfg, st, testprovider.New(), TestLogger(t))
+
+	testCases := []struct {
+		name            string
+		at              string
+		expectedPath    []string
+		expectedLookups int
+	}{
+		{
+			name:         "current",
+			expectedPath: []string{"RUB", "GBP", "EUR"},
+		},
+		{
+			// the shorter path has no history yet, the history of the pairs the search doesn't get to isn't looked up
+			name:            "at",
+			at:              time.Now().Add(-time.Hour).Format(time.RFC3339),
+			expectedPath:    []string{"RUB", "USD", "JPY", "EUR"},
+			expectedLookups: 6,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			*st.lookups = 0
+			q := url.Values{"currency_from": {"RUB"}, "currency_to": {"EUR"}, "value": {"750"}}
+			if tc.at != "" {
+				q.Set("at", tc.at)
+			}
+
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/convert?"+q.Encode(), nil)
+
+			srv.ServeHTTP(rec, req)
+			if !assert.Equal(t, http.StatusOK, rec.Code) {
+				return
+			}
+
+			res := &convertCurrencyResponse{}
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(res))
+			assert.Equal(t, tc.expectedPath, res.Path)
+			assert.Equal(t, tc.expectedLookups, *st.lookups)
+		})
+	}
+}
+
+func TestServer_HandleConvertCurrency_Rounding(t *testing.T) {
+	srv := newServer(TestConfig(t), teststore.New(), testprovider.New(), TestLogger(t))
+
+	r := model.TestRate(t)
+	r.FirstCurrency, r.SecondCurrency = "USD", "JPY"
+	r.Value = decimal.RequireFromString("149.5")
+	_ = srv.store.Rate().Create(context.Background(), r)
+
+	testCases := []struct {
+		name            string
+		rounding        string
+		expectedCode    int
+		expectedRounded string
+	}{
+		{
+			name:            "default",
+			rounding:        "",
+			expectedCode:    http.StatusOK,
+			expectedRounded: "448",
+		},
+		{
+			name:            "half up",
+			rounding:        "half_up",
+			expectedCode:    http.StatusOK,
+			expectedRounded: "449",
+		},
+		{
+			name:            "down",
+			rounding:        "down",
+			expectedCode:    http.StatusOK,
+			expectedRounded: "448",
+		},
+		{
+			name:         "invalid",
+			rounding:     "ceil",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/convert?currency_from=USD&currency_to=JPY&value=3&rounding="+tc.rounding, nil)
+
+			srv.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+
+			if tc.expectedCode == http.StatusOK {
+				res := &convertCurrencyResponse{}
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(res))
+				assert.Equal(t, "448.5", res.ConversionResult.String())
+				assert.Equal(t, tc.expectedRounded, res.RoundedResult.String())
+			}
+		})
+	}
+}
+
+func TestServer_HandleGetRateHistory(t *testing.T) {
+	srv := newServer(TestConfig(t), teststore.New(), testprovider.New(), TestLogger(t))
+
+	r := model.TestRate(t)
+	r.LastUpdateTime = time.Now().Add(-time.Hour)
+	_ = srv.store.Rate().Create(context.Background(), r)
+
+	testCases := []struct {
+		name             string
+		payload          map[string]string
+		expectedCode     int
+		expectedPoints   int
+		expectedInterval string
+	}{
+		{
+			name:           "default period",
+			payload:        map[string]string{},
+			expectedCode:   http.StatusOK,
+			expectedPoints: 1,
+		},
+		{
+			name: "period without points",
+			payload: map[string]string{
+				"start": time.Now().Add(-48 * time.Hour).Format(time.RFC3339),
+				"end":   time.Now().Add(-24 * time.Hour).Format(time.RFC3339),
+			},
+			expectedCode:   http.StatusOK,
+			expectedPoints: 0,
+		},
+		{
+			name: "hourly interval",
+			payload: map[string]string{
+				"interval": "hour",
+			},
+			expectedCode:     http.StatusOK,
+			expectedPoints:   1,
+			expectedInterval: "hour",
+		},
+		{
+			name: "invalid interval",
+			payload: map[string]string{
+				"interval": "week",
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "invalid start",
+			payload: map[string]string{
+				"start": "yesterday",
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "start after end",
+			payload: map[string]string{
+				"start": time.Now().Format(time.RFC3339),
+				"end":   time.Now().Add(-time.Hour).Format(time.RFC3339),
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/rate/usd/rub/history", nil)
+
+			q := req.URL.Query()
+			for pkey, pvalue := range tc.payload {
+				q.Add(pkey, pvalue)
+			}
+			req.URL.RawQuery = q.Encode()
+
+			srv.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+
+			if tc.expectedCode == http.StatusOK {
+				res := make(map[string]json.RawMessage)
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&res))
+
+				var points []*model.RatePoint
+				assert.NoError(t, json.Unmarshal(res["points"], &points))
+				assert.Equal(t, tc.expectedPoints, len(points))
+
+				// the interval is omitted for the raw points
+				interval, ok := res["interval"]
+				if assert.Equal(t, tc.expectedInterval != "", ok) && ok {
+					assert.Equal(t, `"`+tc.expectedInterval+`"`, string(interval))
+				}
+			}
+		})
+	}
+}
+
+func TestServer_HandleListRates(t *testing.T) {
+	srv := newServer(TestConfig(t), teststore.New(), testprovider.New(), TestLogger(t))
+
+	for _, pair := range [][2]string{{"USD", "RUB"}, {"EUR", "USD"}, {"BRL", "CAD"}} {
+		r := model.TestRate(t)
+		r.FirstCurrency, r.SecondCurrency = pair[0], pair[1]
+		_ = srv.store.Rate().Create(context.Background(), r)
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		expectedCode  int
+		expectedLen   int
+		expectedTotal int
+	}{
+		{
+			name:          "all",
+			query:         "",
+			expectedCode:  http.StatusOK,
+			expectedLen:   3,
+			expectedTotal: 3,
+		},
+		{
+			name:          "by currency",
+			query:         "currency=usd",
+			expectedCode:  http.StatusOK,
+			expectedLen:   2,
+			expectedTotal: 2,
+		},
+		{
+			name:          "page",
+			query:         "limit=2&offset=2",
+			expectedCode:  http.StatusOK,
+			expectedLen:   1,
+			expectedTotal: 3,
+		},
+		{
+			name:         "invalid limit",
+			query:        "limit=1000",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "invalid offset",
+			query:        "offset=-1",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/rates?"+tc.query, nil)
+
+			srv.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+
+			if tc.expectedCode == http.StatusOK {
+				res := &listRatesResponse{}
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(res))
+				assert.Equal(t, tc.expectedLen, len(res.Rates))
+				assert.Equal(t, tc.expectedTotal, res.Total)
+			}
+		})
+	}
+}
+
+func TestServer_HandleGetRate(t *testing.T) {
+	srv := newServer(TestConfig(t), teststore.New(), testprovider.New(), TestLogger(t))
+
+	r := model.TestRate(t)
+	_ = srv.store.Rate().Create(context.Background(), r)
+
+	testCases := []struct {
+		name         string
+		path         string
+		expectedCode int
+	}{
+		{
+			name:         "valid",
+			path:         fmt.Sprintf("/api/v1/rate/%d", r.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "not found",
+			path:         "/api/v1/rate/100",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "invalid id",
+			path:         "/api/v1/rate/99999999999999999999",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tc.path, nil)
+
+			srv.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
+
+func TestServer_HandleUpdateRate(t *testing.T) {
+	srv := newServer(TestConfig(t), teststore.New(), testprovider.New(), TestLogger(t))
+
+	r := model.TestRate(t)
+	_ = srv.store.Rate().Create(context.Background(), r)
+
+	testCases := []struct {
+		name         string
+		id           int
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name:         "valid",
+			id:           r.ID,
+			payload:      map[string]string{"value": "80.5"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "not found",
+			id:           100,
+			payload:      map[string]string{"value": "80.5"},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "missing value",
+			id:           r.ID,
+			payload:      map[string]string{},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid payload",
+			id:           r.ID,
+			payload:      "invalid",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "negative value",
+			id:           r.ID,
+			payload:      map[string]string{"value": "-1"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			b := &bytes.Buffer{}
+			_ = json.NewEncoder(b).Encode(tc.payload)
+
+			req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/rate/%d", tc.id), b)
+
+			srv.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	rate, err := srv.store.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "80.5", rate.Value.String())
+	assert.Equal(t, manualRateSource, rate.Source)
+}
+
+func TestServer_HandleDeleteRate(t *testing.T) {
+	srv := newServer(TestConfig(t), teststore.New(), testprovider.New(), TestLogger(t))
+
+	r := model.TestRate(t)
+	_ = srv.store.Rate().Create(context.Background(), r)
+
+	for _, expectedCode := range []int{http.StatusNoContent, http.StatusNotFound} {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/rate/%d", r.ID), nil)
+
+		srv.ServeHTTP(rec, req)
+		assert.Equal(t, expectedCode, rec.Code)
+	}
+}
+
+func TestServer_HandleUpdateRate_Pin(t *testing.T) {
+	srv := newServer(TestConfig(t), teststore.New(), testprovider.New(), TestLogger(t))
+
+	r := model.TestRate(t)
+	_ = srv.store.Rate().Create(context.Background(), r)
+
+	testCases := []struct {
+		name         string
+		payload      map[string]string
+		expectedCode int
+	}{
+		{
+			name: "missing author",
+			payload: map[string]string{
+				"value":        "80.5",
+				"pinned_until": time.Now().Add(time.Hour).Format(time.RFC3339),
+				"reason":       "end of the quarter",
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "pinned until in the past",
+			payload: map[string]string{
+				"value":        "80.5",
+				"pinned_until": time.Now().Add(-time.Hour).Format(time.RFC3339),
+				"author":       "j.doe",
+				"reason":       "end of the quarter",
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "valid",
+			payload: map[string]string{
+				"value":        "80.5",
+				"pinned_until": time.Now().Add(time.Hour).Format(time.RFC3339),
+				"author":       "j.doe",
+				"reason":       "end of the quarter",
+			},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			b := &bytes.Buffer{}
+			_ = json.NewEncoder(b).Encode(tc.payload)
+
+			req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/rate/%d", r.ID), b)
+
+			srv.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	rate, err := srv.store.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assert.True(t, rate.IsPinned(time.Now()))
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/rate/%d/audit", r.ID), nil)
+
+	srv.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var entries []*model.RateAuditEntry
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&entries))
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "j.doe", entries[0].Author)
+	assert.Equal(t, "end of the quarter", entries[0].Reason)
+	assert.NotNil(t, entries[0].PinnedUntil)
+}
+
+func TestServer_HandleGetProviders(t *testing.T) {
+	breaker := provider.NewCircuitBreaker(testprovider.New(), 1, time.Minute)
+	aggregator, err := provider.NewAggregator(provider.StrategyFallback, []provider.RateProvider{breaker}, nil)
+	assert.NoError(t, err)
+
+	srv := newServer(TestConfig(t), teststore.New(), aggregator, TestLogger(t))
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/providers", nil)
+
+	srv.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var statuses []provider.Status
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&statuses))
+	assert.Equal(t, []provider.Status{{Name: testprovider.Name, State: provider.BreakerClosed}}, statuses)
+}
+
+func TestServer_HandleConvertCurrency_Stale(t *testing.T) {
+	r := model.TestRate(t)
+	r.LastUpdateTime = time.Now().Add(-2 * time.Hour)
+
+	testCases := []struct {
+		name            string
+		pairMaxRateAge  map[string]int
+		params          string
+		expectedCode    int
+		expectedStale   bool
+		expectedWarning string
+	}{
+		{
+			name:            "flagged by default",
+			params:          "currency_from=USD&currency_to=RUB&value=1",
+			expectedCode:    http.StatusOK,
+			expectedStale:   true,
+			expectedWarning: staleWarning,
+		},
+		{
+			name:         "rejected",
+			params:       "currency_from=USD&currency_to=RUB&value=1&on_stale=reject",
+			expectedCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:         "max age of the request",
+			params:       "currency_from=USD&currency_to=RUB&value=1&on_stale=reject&max_age=10800",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "no max age",
+			params:       "currency_from=USD&currency_to=RUB&value=1&on_stale=reject&max_age=0",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:           "max age of the pair",
+			pairMaxRateAge: map[string]int{"USD-RUB": 180},
+			params:         "currency_from=RUB&currency_to=USD&value=1&on_stale=reject",
+			expectedCode:   http.StatusOK,
+		},
+		{
+			name:         "past conversion",
+			params:       "currency_from=USD&currency_to=RUB&value=1&on_stale=reject&at=" + url.QueryEscape(time.Now().Add(-time.Hour).Format(time.RFC3339)),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "wrong max age",
+			params:       "currency_from=USD&currency_to=RUB&value=1&max_age=-1",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "wrong on stale",
+			params:       "currency_from=USD&currency_to=RUB&value=1&on_stale=ignore",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := TestConfig(t)
+			cfg.MaxRateAge = 60
+			if tc.pairMaxRateAge != nil {
+				cfg.PairMaxRateAge = tc.pairMaxRateAge
+			}
+
+			st := teststore.New()
+			_ = st.Rate().Create(context.Background(), r)
+			srv := newServer(cfg, st, testprovider.New(), TestLogger(t))
+
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/convert?"+tc.params, nil)
+
+			srv.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, tc.expectedWarning, rec.Header().Get("Warning"))
+
+			if rec.Code == http.StatusOK {
+				var res convertCurrencyResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&res))
+				assert.Equal(t, tc.expectedStale, res.Stale)
+			}
+		})
+	}
+}
+
+func TestServer_HandleGetCacheStats(t *testing.T) {
+	srv := newServer(TestConfig(t), teststore.New(), testprovider.New(), TestLogger(t))
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/cache/stats", nil)
+
+	srv.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	st := cachestore.New(teststore.New(), time.Minute)
+	_ = st.Rate().Create(context.Background(), model.TestRate(t))
+	srv = newServer(TestConfig(t), st, testprovider.New(), TestLogger(t))
+
+	for i := 0; i < 2; i++ {
+		rec = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, "/api/v1/convert?currency_from=USD&currency_to=RUB&value=1", nil)
+
+		srv.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/cache/stats", nil)
+
+	srv.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var stats cachestore.Stats
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&stats))
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+}
+
+// countingStore counts the rates looked up by currencies.
+type countingStore struct {
+	*teststore.Store
+	lookups *int
+}
+
+func (s countingStore) Rate() store.RateRepository {
+	return countingRateRepository{s.Store.Rate(), s.lookups}
+}
+
+type countingRateRepository struct {
+	store.RateRepository
+	lookups *int
+}
+
+func (r countingRateRepository) FindByCurrencies(ctx context.Context, firstCurrency, secondCurrency string) (*model.Rate, error) {
+	*r.lookups++
+	return r.RateRepository.FindByCurrencies(ctx, firstCurrency, secondCurrency)
+}
+
+func TestServer_HandleConvertBatch(t *testing.T) {
+	st := countingStore{teststore.New(), new(int)}
+	_ = st.Store.Rate().Create(context.Background(), model.TestRate(t))
+	srv := newServer(TestConfig(t), st, testprovider.New(), TestLogger(t))
+
+	payload := `[
+		{"id": "1", "currency_from": "USD", "currency_to": "RUB", "value": "10"},
+		{"id": "2", "currency_from": "USD", "currency_to": "RUB", "value": 2.5},
+		{"id": "3", "currency_from": "dollar", "currency_to": "RUB", "value": "1"},
+		{"id": "4", "currency_from": "USD", "currency_to": "JPY", "value": "1"},
+		{"id": "5", "currency_from": "USD", "currency_to": "RUB"},
+		{"id": "6", "currency_from": "usd", "currency_to": "Rub", "value": "1"},
+		{"id": "7", "currency_from": "USD", "currency_to": "RUB", "value": "abc"},
+		{"id": "8", "currency_from": 840, "currency_to": "RUB", "value": "1"},
+		null
+	]`
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/convert/batch?rounding=down", bytes.NewBufferString(payload))
+
+	srv.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	res := &convertBatchResponse{}
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(res))
+	if !assert.Equal(t, 9, len(res.Results)) {
+		return
+	}
+	assert.Equal(t, 6, res.Failed)
+
+	for i, expectedID := range []string{"1", "2", "3", "4", "5", "6", "7", "8", ""} {
+		assert.Equal(t, expectedID, res.Results[i].ID)
+	}
+
+	assert.Equal(t, "1214.1", res.Results[0].Result.RoundedResult.String())
+	assert.Equal(t, "303.52", res.Results[1].Result.RoundedResult.String())
+	assert.Equal(t, string(currency.RoundDown), res.Results[1].Result.Query.Rounding)
+	assert.Equal(t, codeUnsupportedCurrency, res.Results[2].Error.Code)
+	assert.Equal(t, codeRateNotFound, res.Results[3].Error.Code)
+	assert.Equal(t, codeInvalidRequest, res.Results[4].Error.Code)
+	if assert.NotNil(t, res.Results[5].Result) {
+		assert.Equal(t, []string{"USD", "RUB"}, res.Results[5].Result.Path)
+	}
+	for _, result := range res.Results[6:] {
+		if assert.NotNil(t, result.Error) {
+			assert.Equal(t, codeInvalidRequest, result.Error.Code)
+		}
+	}
+
+	// USD-RUB is looked up once, USD-JPY and JPY-USD once each
+	assert.Equal(t, 3, *st.lookups)
+}
+
+func TestServer_HandleConvertBatch_Invalid(t *testing.T) {
+	srv := newServer(TestConfig(t), teststore.New(), testprovider.New(), TestLogger(t))
+
+	testCases := []struct {
+		name         string
+		query        string
+		payload      string
+		expectedCode int
+	}{
+		{
+			name:         "invalid payload",
+			payload:      `{"currency_from": "USD"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "empty",
+			payload:      `[]`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "too many items",
+			payload:      "[" + strings.Repeat(`{"currency_from": "USD", "currency_to": "RUB", "value": "1"},`, maxBatchItems) + `{}]`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "invalid options",
+			query:        "rounding=nearest",
+			payload:      `[{"currency_from": "USD", "currency_to": "RUB", "value": "1"}]`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/convert/batch?"+tc.query, bytes.NewBufferString(tc.payload))
+
+			srv.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, problemContentType, rec.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestServer_HandleCreateRates(t *testing.T) {
+	st := countingStore{teststore.New(), new(int)}
+	_ = st.Store.Rate().Create(context.Background(), model.TestRate(t))
+
+	prov := &countingProvider{RateProvider: testprovider.New()}
+	srv := newServer(TestConfig(t), st, prov, TestLogger(t))
+
+	// the supported currencies the test provider has no rates of
+	var unquoted []string
+	for _, code := range currency.Codes() {
+		if !strings.Contains("USD EUR GBP JPY CNY CAD BRL KWD RUB", code) {
+			unquoted = append(unquoted, code)
+		}
+	}
+
+	testCases := []struct {
+		name                string
+		payload             string
+		expectedCode        int
+		expectedCreated     []string
+		expectedExisting    []string
+		expectedUnsupported []string
+		expectedCalls       int
+	}{
+		{
+			name:                "list",
+			payload:             `{"base_currency": "USD", "quote_currencies": ["RUB", "eur", "EUR", "USD", "XYZ", "CHF", "JPY"]}`,
+			expectedCode:        http.StatusCreated,
+			expectedCreated:     []string{"EUR", "JPY"},
+			expectedExisting:    []string{"RUB"},
+			expectedUnsupported: []string{"XYZ", "CHF"},
+			expectedCalls:       1,
+		},
+		{
+			name:             "nothing to create",
+			payload:          `{"base_currency": "USD", "quote_currencies": ["RUB", "EUR"]}`,
+			expectedCode:     http.StatusOK,
+			expectedExisting: []string{"RUB", "EUR"},
+		},
+		{
+			name:                "all supported",
+			payload:             `{"base_currency": "USD", "all": true}`,
+			expectedCode:        http.StatusCreated,
+			expectedCreated:     []string{"BRL", "CAD", "CNY", "GBP", "KWD"},
+			expectedExisting:    []string{"EUR", "JPY", "RUB"},
+			expectedUnsupported: unquoted,
+			expectedCalls:       1,
+		},
+		{
+			name:         "missing quote currencies",
+			payload:      `{"base_currency": "USD"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "both quote currencies and all",
+			payload:      `{"base_currency": "USD", "quote_currencies": ["RUB"], "all": true}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "unsupported base currency",
+			payload:      `{"base_currency": "dollar", "all": true}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:          "unavailable upstream",
+			payload:       `{"base_currency": "CHF", "quote_currencies": ["USD"]}`,
+			expectedCode:  http.StatusServiceUnavailable,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prov.calls = make(map[string]int)
+
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/rates", bytes.NewBufferString(tc.payload))
+
+			srv.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, 0, *st.lookups)
+			assert.LessOrEqual(t, len(prov.calls), 1)
+			for _, calls := range prov.calls {
+				assert.Equal(t, tc.expectedCalls, calls)
+			}
+
+			if rec.Code != http.StatusOK && rec.Code != http.StatusCreated {
+				return
+			}
+
+			res := &createRatesResponse{}
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(res))
+
+			created := make([]string, 0, len(res.Created))
+			for _, rate := range res.Created {
+				assert.Equal(t, "USD", rate.FirstCurrency)
+				created = append(created, rate.SecondCurrency)
+			}
+
+			assert.ElementsMatch(t, tc.expectedCreated, created)
+			assert.ElementsMatch(t, tc.expectedExisting, res.Existing)
+			assert.ElementsMatch(t, tc.expectedUnsupported, res.Unsupported)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package apiserver
+
+import (
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/config"
+	"time"
+)
+
+// stalePolicy defines what happens to a conversion made at a stale rate.
+type stalePolicy string
+
+const (
+	// stalePolicyFlag makes the conversion but marks the result as stale.
+	stalePolicyFlag stalePolicy = "flag"
+
+	// stalePolicyReject refuses to make the conversion.
+	stalePolicyReject stalePolicy = "reject"
+)
+
+// staleWarning is the value of the Warning header of a response containing a conversion made at a stale rate.
+const staleWarning = `110 - "Response is Stale"`
+
+func parseStalePolicy(s string) (stalePolicy, bool) {
+	switch policy := stalePolicy(s); policy {
+	case stalePolicyFlag, stalePolicyReject:
+		return policy, true
+	default:
+		return "", false
+	}
+}
+
+// staleness decides whether rates are too old to be used for conversions.
+type staleness struct {
+	policy     stalePolicy
+	maxAge     time.Duration
+	pairMaxAge map[string]time.Duration
+}
+
+func newStaleness(cfg *config.Config) *staleness {
+	policy, ok := parseStalePolicy(cfg.StaleRatePolicy)
+	if !ok {
+		policy = stalePolicyFlag
+	}
+
+	pairMaxAge := make(map[string]time.Duration, len(cfg.PairMaxRateAge))
+	for pair, minutes := range cfg.PairMaxRateAge {
+		pairMaxAge[pair] = time.Minute * time.Duration(minutes)
+	}
+
+	return &staleness{
+		policy:     policy,
+		maxAge:     time.Minute * time.Duration(cfg.MaxRateAge),
+		pairMaxAge: pairMaxAge,
+	}
+}
+
+// isStale reports whether the rate of any leg is older than its max age at the moment now.
+// The max age of the request overrides the configured ones unless it's nil, 0 means no limit.
+func (st *staleness) isStale(legs []*conversionLeg, maxAge *time.Duration, now time.Time) bool {
+	for _, leg := range legs {
+		legMaxAge := st.legMaxAge(leg)
+		if maxAge != nil {
+			legMaxAge = *maxAge
+		}
+
+		if legMaxAge > 0 && now.Sub(leg.LastUpdateTime) > legMaxAge {
+			return true
+		}
+	}
+
+	return false
+}
+
+// legMaxAge returns the max age of the stored pair the rate of the leg was taken from.
+func (st *staleness) legMaxAge(leg *conversionLeg) time.Duration {
+	pair := leg.CurrencyFrom + "-" + leg.CurrencyTo
+	if leg.Inverse {
+		pair = leg.CurrencyTo + "-" + leg.CurrencyFrom
+	}
+
+	if maxAge, ok := st.pairMaxAge[pair]; ok {
+		return maxAge
+	}
+
+	return st.maxAge
+}
@@ -3,6 +3,7 @@ package apiserver
 import (
 	"github.com/sirupsen/logrus"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/config"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider/testprovider"
 	"os"
 	"testing"
 )
@@ -17,6 +18,7 @@ func TestConfig(t *testing.T) *config.Config {
 	t.Helper()
 
 	cfg := config.New()
+	cfg.RateProviders = []string{testprovider.Name}
 
 	cfg.CurrencyAPIKey = os.Getenv("TEST_CURRENCY_API_KEY")
 	if cfg.CurrencyAPIKey == "" {
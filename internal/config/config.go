@@ -1,29 +1,101 @@
 package config
 
 import (
+	"errors"
 	"github.com/BurntSushi/toml"
 	"github.com/joho/godotenv"
 	"os"
 )
 
+var (
+	errWrongUpdateInterval      = errors.New("update_interval should be a positive number of minutes")
+	errWrongLeaderCheckInterval = errors.New("leader_check_interval should be a positive number of seconds")
+)
+
+type ProviderConfig struct {
+	URL    string  `toml:"url"`    // API URL, the provider's default one is used if empty
+	Weight float64 `toml:"weight"` // weight of the provider's quotes in the "weighted" aggregation
+
+	Timeout int `toml:"timeout"` // in seconds, timeout of a request to the provider, 10 if 0
+
+	RPS        float64 `toml:"rps"`         // max requests per second, unlimited if 0
+	Burst      int     `toml:"burst"`       // max requests at once
+	DailyQuota int     `toml:"daily_quota"` // max requests per UTC day, unlimited if 0
+
+	Retries       int `toml:"retries"`         // max retries of a request failed with a transient error
+	RetryDelay    int `toml:"retry_delay"`     // in milliseconds, base delay of the exponential backoff
+	RetryMaxDelay int `toml:"retry_max_delay"` // in milliseconds, max delay between retries
+
+	BreakerThreshold int `toml:"breaker_threshold"` // consecutive failures that open the circuit, disabled if 0
+	BreakerTimeout   int `toml:"breaker_timeout"`   // in seconds, time the circuit stays open before a trial request
+}
+
 type Config struct {
 	BindAddr       string `toml:"bind_addr"`       // server address
+	AutoMigrate    bool   `toml:"auto_migrate"`    // apply the pending migrations on start
 	UpdateInterval int    `toml:"update_interval"` // in minutes
+	UpdateWorkers  int    `toml:"update_workers"`  // number of base currencies updated concurrently
+
+	ShutdownTimeout int `toml:"shutdown_timeout"` // in seconds, time given to in-flight requests to finish on shutdown
+	RequestTimeout  int `toml:"request_timeout"`  // in seconds, time after which an API request is cancelled
+
+	MaxConversionHops int `toml:"max_conversion_hops"` // max number of rates in a chain a value can be converted through
+
+	MaxRateAge      int            `toml:"max_rate_age"`      // in minutes, rates older than that are stale, no limit if 0
+	PairMaxRateAge  map[string]int `toml:"pair_max_rate_age"` // in minutes, max_rate_age of the pairs like "USD-RUB"
+	StaleRatePolicy string         `toml:"stale_rate_policy"` // "flag" or "reject" the conversions at stale rates
+
+	CacheTTL int `toml:"cache_ttl"` // in seconds, time the rates are cached for, no caching if 0
+
+	LeaderLockKey       int64 `toml:"leader_lock_key"`       // key of the advisory lock held by the instance updating the rates
+	LeaderCheckInterval int   `toml:"leader_check_interval"` // in seconds, how often the leadership is tried to acquire and checked
+
+	RateProviders   []string                  `toml:"rate_providers"`   // names of the exchange rates providers in order of priority
+	RateAggregation string                    `toml:"rate_aggregation"` // "fallback", "median" or "weighted"
+	Providers       map[string]ProviderConfig `toml:"providers"`        // settings of the providers by their names
 
 	CurrencyAPIKey string
-	DatabaseURL    string
+	DatabaseURL    string // Postgres connection string or "sqlite://<path>" to store everything in a SQLite file
+	RedisURL       string // the rates are also cached in Redis, shared by all the instances, if set
 }
 
 func New() *Config {
 	return &Config{
-		BindAddr:       ":8080",
-		UpdateInterval: 10,
+		BindAddr:            ":8080",
+		UpdateInterval:      10,
+		UpdateWorkers:       4,
+		ShutdownTimeout:     30,
+		RequestTimeout:      30,
+		MaxConversionHops:   3,
+		StaleRatePolicy:     "flag",
+		LeaderLockKey:       1918989413,
+		LeaderCheckInterval: 10,
+		PairMaxRateAge:      make(map[string]int),
+		RateProviders:       []string{"freecurrencyapi"},
+		RateAggregation:     "fallback",
+		Providers:           make(map[string]ProviderConfig),
 	}
 }
 
 func (c *Config) LoadTomlConfig(configPath string) error {
-	_, err := toml.DecodeFile(configPath, c)
-	return err
+	if _, err := toml.DecodeFile(configPath, c); err != nil {
+		return err
+	}
+
+	return c.validate()
+}
+
+// validate checks the intervals the tickers are created with, they panic if an interval isn't positive.
+func (c *Config) validate() error {
+	if c.UpdateInterval <= 0 {
+		return errWrongUpdateInterval
+	}
+
+	if c.LeaderCheckInterval <= 0 {
+		return errWrongLeaderCheckInterval
+	}
+
+	return nil
 }
 
 func (c *Config) LoadEnvConfig(configPath string) error {
@@ -33,6 +105,7 @@ func (c *Config) LoadEnvConfig(configPath string) error {
 
 	c.DatabaseURL = os.Getenv("DATABASE_URL")
 	c.CurrencyAPIKey = os.Getenv("CURRENCY_API_KEY")
+	c.RedisURL = os.Getenv("REDIS_URL")
 
 	return nil
 }
@@ -0,0 +1,60 @@
+package config_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig_LoadTomlConfig(t *testing.T) {
+	testCases := []struct {
+		name    string
+		toml    string
+		isValid bool
+	}{
+		{
+			name:    "valid",
+			toml:    "update_interval = 5\nleader_check_interval = 5",
+			isValid: true,
+		},
+		{
+			name:    "defaults",
+			toml:    "bind_addr = \":8081\"",
+			isValid: true,
+		},
+		{
+			name: "zero update interval",
+			toml: "update_interval = 0",
+		},
+		{
+			name: "negative update interval",
+			toml: "update_interval = -1",
+		},
+		{
+			name: "zero leader check interval",
+			toml: "leader_check_interval = 0",
+		},
+		{
+			name: "negative leader check interval",
+			toml: "leader_check_interval = -1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "apiserver.toml")
+			if err := os.WriteFile(path, []byte(tc.toml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			err := config.New().LoadTomlConfig(path)
+			if tc.isValid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package currency
+
+import (
+	"github.com/shopspring/decimal"
+	"sort"
+)
+
+// NoMinorUnits is set for the currencies (precious metals, SDR, etc.) whose minor unit isn't applicable.
+const NoMinorUnits = -1
+
+// Currency ...
+type Currency struct {
+	Code       string
+	Name       string
+	MinorUnits int32 // number of digits after the decimal separator
+}
+
+// RoundingMode ...
+type RoundingMode string
+
+const (
+	// RoundHalfEven rounds to the nearest value, ties go to the even digit (banker's rounding).
+	RoundHalfEven RoundingMode = "half_even"
+
+	// RoundHalfUp rounds to the nearest value, ties go away from zero.
+	RoundHalfUp RoundingMode = "half_up"
+
+	// RoundDown rounds towards zero.
+	RoundDown RoundingMode = "down"
+
+	// RoundUp rounds away from zero.
+	RoundUp RoundingMode = "up"
+)
+
+// DefaultRoundingMode ...
+const DefaultRoundingMode = RoundHalfEven
+
+// Lookup ...
+func Lookup(code string) (Currency, bool) {
+	c, ok := currencies[code]
+	return c, ok
+}
+
+// IsSupported ...
+func IsSupported(code string) bool {
+	_, ok := currencies[code]
+	return ok
+}
+
+// Codes returns the sorted codes of all supported currencies.
+func Codes() []string {
+	codes := make([]string, 0, len(currencies))
+	for code := range currencies {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes
+}
+
+// ParseRoundingMode ...
+func ParseRoundingMode(s string) (RoundingMode, bool) {
+	switch RoundingMode(s) {
+	case "":
+		return DefaultRoundingMode, true
+	case RoundHalfEven, RoundHalfUp, RoundDown, RoundUp:
+		return RoundingMode(s), true
+	default:
+		return "", false
+	}
+}
+
+// Round rounds the amount to the minor unit of the currency.
+// The amount is returned as is if the currency is unknown or has no minor unit.
+func Round(amount decimal.Decimal, code string, mode RoundingMode) decimal.Decimal {
+	c, ok := currencies[code]
+	if !ok || c.MinorUnits == NoMinorUnits {
+		return amount
+	}
+
+	switch mode {
+	case RoundHalfUp:
+		return amount.Round(c.MinorUnits)
+	case RoundDown:
+		return amount.RoundDown(c.MinorUnits)
+	case RoundUp:
+		return amount.RoundUp(c.MinorUnits)
+	default:
+		return amount.RoundBank(c.MinorUnits)
+	}
+}
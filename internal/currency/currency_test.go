@@ -0,0 +1,54 @@
+package currency_test
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/currency"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	c, ok := currency.Lookup("KWD")
+	assert.True(t, ok)
+	assert.Equal(t, int32(3), c.MinorUnits)
+
+	_, ok = currency.Lookup("dollar")
+	assert.False(t, ok)
+}
+
+func TestRound(t *testing.T) {
+	testCases := []struct {
+		name     string
+		amount   string
+		code     string
+		mode     currency.RoundingMode
+		expected string
+	}{
+		{name: "half even down", amount: "2.345", code: "USD", mode: currency.RoundHalfEven, expected: "2.34"},
+		{name: "half even up", amount: "2.355", code: "USD", mode: currency.RoundHalfEven, expected: "2.36"},
+		{name: "half up", amount: "2.345", code: "USD", mode: currency.RoundHalfUp, expected: "2.35"},
+		{name: "half up negative", amount: "-2.345", code: "USD", mode: currency.RoundHalfUp, expected: "-2.35"},
+		{name: "down", amount: "2.349", code: "USD", mode: currency.RoundDown, expected: "2.34"},
+		{name: "up", amount: "2.341", code: "USD", mode: currency.RoundUp, expected: "2.35"},
+		{name: "zero minor units", amount: "149.5", code: "JPY", mode: currency.RoundHalfEven, expected: "150"},
+		{name: "three minor units", amount: "0.30745", code: "KWD", mode: currency.RoundHalfUp, expected: "0.307"},
+		{name: "no minor units", amount: "0.123456", code: "XAU", mode: currency.RoundHalfUp, expected: "0.123456"},
+		{name: "unknown currency", amount: "1.23456", code: "ABC", mode: currency.RoundHalfUp, expected: "1.23456"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rounded := currency.Round(decimal.RequireFromString(tc.amount), tc.code, tc.mode)
+			assert.Equal(t, tc.expected, rounded.String())
+		})
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	mode, ok := currency.ParseRoundingMode("")
+	assert.True(t, ok)
+	assert.Equal(t, currency.DefaultRoundingMode, mode)
+
+	_, ok = currency.ParseRoundingMode("ceil")
+	assert.False(t, ok)
+}
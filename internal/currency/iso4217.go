@@ -0,0 +1,181 @@
+package currency
+
+// currencies is the list of the active ISO 4217 currencies and funds
+// except for the bond market units and the testing and "no currency" codes.
+var currencies = map[string]Currency{
+	"AED": {Code: "AED", MinorUnits: 2, Name: "UAE Dirham"},
+	"AFN": {Code: "AFN", MinorUnits: 2, Name: "Afghani"},
+	"ALL": {Code: "ALL", MinorUnits: 2, Name: "Lek"},
+	"AMD": {Code: "AMD", MinorUnits: 2, Name: "Armenian Dram"},
+	"ANG": {Code: "ANG", MinorUnits: 2, Name: "Netherlands Antillean Guilder"},
+	"AOA": {Code: "AOA", MinorUnits: 2, Name: "Kwanza"},
+	"ARS": {Code: "ARS", MinorUnits: 2, Name: "Argentine Peso"},
+	"AUD": {Code: "AUD", MinorUnits: 2, Name: "Australian Dollar"},
+	"AWG": {Code: "AWG", MinorUnits: 2, Name: "Aruban Florin"},
+	"AZN": {Code: "AZN", MinorUnits: 2, Name: "Azerbaijan Manat"},
+	"BAM": {Code: "BAM", MinorUnits: 2, Name: "Convertible Mark"},
+	"BBD": {Code: "BBD", MinorUnits: 2, Name: "Barbados Dollar"},
+	"BDT": {Code: "BDT", MinorUnits: 2, Name: "Taka"},
+	"BGN": {Code: "BGN", MinorUnits: 2, Name: "Bulgarian Lev"},
+	"BHD": {Code: "BHD", MinorUnits: 3, Name: "Bahraini Dinar"},
+	"BIF": {Code: "BIF", MinorUnits: 0, Name: "Burundi Franc"},
+	"BMD": {Code: "BMD", MinorUnits: 2, Name: "Bermudian Dollar"},
+	"BND": {Code: "BND", MinorUnits: 2, Name: "Brunei Dollar"},
+	"BOB": {Code: "BOB", MinorUnits: 2, Name: "Boliviano"},
+	"BOV": {Code: "BOV", MinorUnits: 2, Name: "Mvdol"},
+	"BRL": {Code: "BRL", MinorUnits: 2, Name: "Brazilian Real"},
+	"BSD": {Code: "BSD", MinorUnits: 2, Name: "Bahamian Dollar"},
+	"BTN": {Code: "BTN", MinorUnits: 2, Name: "Ngultrum"},
+	"BWP": {Code: "BWP", MinorUnits: 2, Name: "Pula"},
+	"BYN": {Code: "BYN", MinorUnits: 2, Name: "Belarusian Ruble"},
+	"BZD": {Code: "BZD", MinorUnits: 2, Name: "Belize Dollar"},
+	"CAD": {Code: "CAD", MinorUnits: 2, Name: "Canadian Dollar"},
+	"CDF": {Code: "CDF", MinorUnits: 2, Name: "Congolese Franc"},
+	"CHE": {Code: "CHE", MinorUnits: 2, Name: "WIR Euro"},
+	"CHF": {Code: "CHF", MinorUnits: 2, Name: "Swiss Franc"},
+	"CHW": {Code: "CHW", MinorUnits: 2, Name: "WIR Franc"},
+	"CLF": {Code: "CLF", MinorUnits: 4, Name: "Unidad de Fomento"},
+	"CLP": {Code: "CLP", MinorUnits: 0, Name: "Chilean Peso"},
+	"CNY": {Code: "CNY", MinorUnits: 2, Name: "Yuan Renminbi"},
+	"COP": {Code: "COP", MinorUnits: 2, Name: "Colombian Peso"},
+	"COU": {Code: "COU", MinorUnits: 2, Name: "Unidad de Valor Real"},
+	"CRC": {Code: "CRC", MinorUnits: 2, Name: "Costa Rican Colon"},
+	"CUP": {Code: "CUP", MinorUnits: 2, Name: "Cuban Peso"},
+	"CVE": {Code: "CVE", MinorUnits: 2, Name: "Cabo Verde Escudo"},
+	"CZK": {Code: "CZK", MinorUnits: 2, Name: "Czech Koruna"},
+	"DJF": {Code: "DJF", MinorUnits: 0, Name: "Djibouti Franc"},
+	"DKK": {Code: "DKK", MinorUnits: 2, Name: "Danish Krone"},
+	"DOP": {Code: "DOP", MinorUnits: 2, Name: "Dominican Peso"},
+	"DZD": {Code: "DZD", MinorUnits: 2, Name: "Algerian Dinar"},
+	"EGP": {Code: "EGP", MinorUnits: 2, Name: "Egyptian Pound"},
+	"ERN": {Code: "ERN", MinorUnits: 2, Name: "Nakfa"},
+	"ETB": {Code: "ETB", MinorUnits: 2, Name: "Ethiopian Birr"},
+	"EUR": {Code: "EUR", MinorUnits: 2, Name: "Euro"},
+	"FJD": {Code: "FJD", MinorUnits: 2, Name: "Fiji Dollar"},
+	"FKP": {Code: "FKP", MinorUnits: 2, Name: "Falkland Islands Pound"},
+	"GBP": {Code: "GBP", MinorUnits: 2, Name: "Pound Sterling"},
+	"GEL": {Code: "GEL", MinorUnits: 2, Name: "Lari"},
+	"GHS": {Code: "GHS", MinorUnits: 2, Name: "Ghana Cedi"},
+	"GIP": {Code: "GIP", MinorUnits: 2, Name: "Gibraltar Pound"},
+	"GMD": {Code: "GMD", MinorUnits: 2, Name: "Dalasi"},
+	"GNF": {Code: "GNF", MinorUnits: 0, Name: "Guinean Franc"},
+	"GTQ": {Code: "GTQ", MinorUnits: 2, Name: "Quetzal"},
+	"GYD": {Code: "GYD", MinorUnits: 2, Name: "Guyana Dollar"},
+	"HKD": {Code: "HKD", MinorUnits: 2, Name: "Hong Kong Dollar"},
+	"HNL": {Code: "HNL", MinorUnits: 2, Name: "Lempira"},
+	"HTG": {Code: "HTG", MinorUnits: 2, Name: "Gourde"},
+	"HUF": {Code: "HUF", MinorUnits: 2, Name: "Forint"},
+	"IDR": {Code: "IDR", MinorUnits: 2, Name: "Rupiah"},
+	"ILS": {Code: "ILS", MinorUnits: 2, Name: "New Israeli Sheqel"},
+	"INR": {Code: "INR", MinorUnits: 2, Name: "Indian Rupee"},
+	"IQD": {Code: "IQD", MinorUnits: 3, Name: "Iraqi Dinar"},
+	"IRR": {Code: "IRR", MinorUnits: 2, Name: "Iranian Rial"},
+	"ISK": {Code: "ISK", MinorUnits: 0, Name: "Iceland Krona"},
+	"JMD": {Code: "JMD", MinorUnits: 2, Name: "Jamaican Dollar"},
+	"JOD": {Code: "JOD", MinorUnits: 3, Name: "Jordanian Dinar"},
+	"JPY": {Code: "JPY", MinorUnits: 0, Name: "Yen"},
+	"KES": {Code: "KES", MinorUnits: 2, Name: "Kenyan Shilling"},
+	"KGS": {Code: "KGS", MinorUnits: 2, Name: "Som"},
+	"KHR": {Code: "KHR", MinorUnits: 2, Name: "Riel"},
+	"KMF": {Code: "KMF", MinorUnits: 0, Name: "Comorian Franc"},
+	"KPW": {Code: "KPW", MinorUnits: 2, Name: "North Korean Won"},
+	"KRW": {Code: "KRW", MinorUnits: 0, Name: "Won"},
+	"KWD": {Code: "KWD", MinorUnits: 3, Name: "Kuwaiti Dinar"},
+	"KYD": {Code: "KYD", MinorUnits: 2, Name: "Cayman Islands Dollar"},
+	"KZT": {Code: "KZT", MinorUnits: 2, Name: "Tenge"},
+	"LAK": {Code: "LAK", MinorUnits: 2, Name: "Lao Kip"},
+	"LBP": {Code: "LBP", MinorUnits: 2, Name: "Lebanese Pound"},
+	"LKR": {Code: "LKR", MinorUnits: 2, Name: "Sri Lanka Rupee"},
+	"LRD": {Code: "LRD", MinorUnits: 2, Name: "Liberian Dollar"},
+	"LSL": {Code: "LSL", MinorUnits: 2, Name: "Loti"},
+	"LYD": {Code: "LYD", MinorUnits: 3, Name: "Libyan Dinar"},
+	"MAD": {Code: "MAD", MinorUnits: 2, Name: "Moroccan Dirham"},
+	"MDL": {Code: "MDL", MinorUnits: 2, Name: "Moldovan Leu"},
+	"MGA": {Code: "MGA", MinorUnits: 2, Name: "Malagasy Ariary"},
+	"MKD": {Code: "MKD", MinorUnits: 2, Name: "Denar"},
+	"MMK": {Code: "MMK", MinorUnits: 2, Name: "Kyat"},
+	"MNT": {Code: "MNT", MinorUnits: 2, Name: "Tugrik"},
+	"MOP": {Code: "MOP", MinorUnits: 2, Name: "Pataca"},
+	"MRU": {Code: "MRU", MinorUnits: 2, Name: "Ouguiya"},
+	"MUR": {Code: "MUR", MinorUnits: 2, Name: "Mauritius Rupee"},
+	"MVR": {Code: "MVR", MinorUnits: 2, Name: "Rufiyaa"},
+	"MWK": {Code: "MWK", MinorUnits: 2, Name: "Malawi Kwacha"},
+	"MXN": {Code: "MXN", MinorUnits: 2, Name: "Mexican Peso"},
+	"MXV": {Code: "MXV", MinorUnits: 2, Name: "Mexican Unidad de Inversion (UDI)"},
+	"MYR": {Code: "MYR", MinorUnits: 2, Name: "Malaysian Ringgit"},
+	"MZN": {Code: "MZN", MinorUnits: 2, Name: "Mozambique Metical"},
+	"NAD": {Code: "NAD", MinorUnits: 2, Name: "Namibia Dollar"},
+	"NGN": {Code: "NGN", MinorUnits: 2, Name: "Naira"},
+	"NIO": {Code: "NIO", MinorUnits: 2, Name: "Cordoba Oro"},
+	"NOK": {Code: "NOK", MinorUnits: 2, Name: "Norwegian Krone"},
+	"NPR": {Code: "NPR", MinorUnits: 2, Name: "Nepalese Rupee"},
+	"NZD": {Code: "NZD", MinorUnits: 2, Name: "New Zealand Dollar"},
+	"OMR": {Code: "OMR", MinorUnits: 3, Name: "Rial Omani"},
+	"PAB": {Code: "PAB", MinorUnits: 2, Name: "Balboa"},
+	"PEN": {Code: "PEN", MinorUnits: 2, Name: "Sol"},
+	"PGK": {Code: "PGK", MinorUnits: 2, Name: "Kina"},
+	"PHP": {Code: "PHP", MinorUnits: 2, Name: "Philippine Peso"},
+	"PKR": {Code: "PKR", MinorUnits: 2, Name: "Pakistan Rupee"},
+	"PLN": {Code: "PLN", MinorUnits: 2, Name: "Zloty"},
+	"PYG": {Code: "PYG", MinorUnits: 0, Name: "Guarani"},
+	"QAR": {Code: "QAR", MinorUnits: 2, Name: "Qatari Rial"},
+	"RON": {Code: "RON", MinorUnits: 2, Name: "Romanian Leu"},
+	"RSD": {Code: "RSD", MinorUnits: 2, Name: "Serbian Dinar"},
+	"RUB": {Code: "RUB", MinorUnits: 2, Name: "Russian Ruble"},
+	"RWF": {Code: "RWF", MinorUnits: 0, Name: "Rwanda Franc"},
+	"SAR": {Code: "SAR", MinorUnits: 2, Name: "Saudi Riyal"},
+	"SBD": {Code: "SBD", MinorUnits: 2, Name: "Solomon Islands Dollar"},
+	"SCR": {Code: "SCR", MinorUnits: 2, Name: "Seychelles Rupee"},
+	"SDG": {Code: "SDG", MinorUnits: 2, Name: "Sudanese Pound"},
+	"SEK": {Code: "SEK", MinorUnits: 2, Name: "Swedish Krona"},
+	"SGD": {Code: "SGD", MinorUnits: 2, Name: "Singapore Dollar"},
+	"SHP": {Code: "SHP", MinorUnits: 2, Name: "Saint Helena Pound"},
+	"SLE": {Code: "SLE", MinorUnits: 2, Name: "Leone"},
+	"SLL": {Code: "SLL", MinorUnits: 2, Name: "Leone (old)"},
+	"SOS": {Code: "SOS", MinorUnits: 2, Name: "Somali Shilling"},
+	"SRD": {Code: "SRD", MinorUnits: 2, Name: "Surinam Dollar"},
+	"SSP": {Code: "SSP", MinorUnits: 2, Name: "South Sudanese Pound"},
+	"STN": {Code: "STN", MinorUnits: 2, Name: "Dobra"},
+	"SVC": {Code: "SVC", MinorUnits: 2, Name: "El Salvador Colon"},
+	"SYP": {Code: "SYP", MinorUnits: 2, Name: "Syrian Pound"},
+	"SZL": {Code: "SZL", MinorUnits: 2, Name: "Lilangeni"},
+	"THB": {Code: "THB", MinorUnits: 2, Name: "Baht"},
+	"TJS": {Code: "TJS", MinorUnits: 2, Name: "Somoni"},
+	"TMT": {Code: "TMT", MinorUnits: 2, Name: "Turkmenistan New Manat"},
+	"TND": {Code: "TND", MinorUnits: 3, Name: "Tunisian Dinar"},
+	"TOP": {Code: "TOP", MinorUnits: 2, Name: "Pa'anga"},
+	"TRY": {Code: "TRY", MinorUnits: 2, Name: "Turkish Lira"},
+	"TTD": {Code: "TTD", MinorUnits: 2, Name: "Trinidad and Tobago Dollar"},
+	"TWD": {Code: "TWD", MinorUnits: 2, Name: "New Taiwan Dollar"},
+	"TZS": {Code: "TZS", MinorUnits: 2, Name: "Tanzanian Shilling"},
+	"UAH": {Code: "UAH", MinorUnits: 2, Name: "Hryvnia"},
+	"UGX": {Code: "UGX", MinorUnits: 0, Name: "Uganda Shilling"},
+	"USD": {Code: "USD", MinorUnits: 2, Name: "US Dollar"},
+	"USN": {Code: "USN", MinorUnits: 2, Name: "US Dollar (Next day)"},
+	"UYI": {Code: "UYI", MinorUnits: 0, Name: "Uruguay Peso en Unidades Indexadas (UI)"},
+	"UYU": {Code: "UYU", MinorUnits: 2, Name: "Peso Uruguayo"},
+	"UYW": {Code: "UYW", MinorUnits: 4, Name: "Unidad Previsional"},
+	"UZS": {Code: "UZS", MinorUnits: 2, Name: "Uzbekistan Sum"},
+	"VED": {Code: "VED", MinorUnits: 2, Name: "Bolivar Soberano"},
+	"VES": {Code: "VES", MinorUnits: 2, Name: "Bolivar Soberano"},
+	"VND": {Code: "VND", MinorUnits: 0, Name: "Dong"},
+	"VUV": {Code: "VUV", MinorUnits: 0, Name: "Vatu"},
+	"WST": {Code: "WST", MinorUnits: 2, Name: "Tala"},
+	"XAF": {Code: "XAF", MinorUnits: 0, Name: "CFA Franc BEAC"},
+	"XAG": {Code: "XAG", MinorUnits: NoMinorUnits, Name: "Silver"},
+	"XAU": {Code: "XAU", MinorUnits: NoMinorUnits, Name: "Gold"},
+	"XCD": {Code: "XCD", MinorUnits: 2, Name: "East Caribbean Dollar"},
+	"XCG": {Code: "XCG", MinorUnits: 2, Name: "Caribbean Guilder"},
+	"XDR": {Code: "XDR", MinorUnits: NoMinorUnits, Name: "SDR (Special Drawing Right)"},
+	"XOF": {Code: "XOF", MinorUnits: 0, Name: "CFA Franc BCEAO"},
+	"XPD": {Code: "XPD", MinorUnits: NoMinorUnits, Name: "Palladium"},
+	"XPF": {Code: "XPF", MinorUnits: 0, Name: "CFP Franc"},
+	"XPT": {Code: "XPT", MinorUnits: NoMinorUnits, Name: "Platinum"},
+	"XSU": {Code: "XSU", MinorUnits: NoMinorUnits, Name: "Sucre"},
+	"XUA": {Code: "XUA", MinorUnits: NoMinorUnits, Name: "ADB Unit of Account"},
+	"YER": {Code: "YER", MinorUnits: 2, Name: "Yemeni Rial"},
+	"ZAR": {Code: "ZAR", MinorUnits: 2, Name: "Rand"},
+	"ZMW": {Code: "ZMW", MinorUnits: 2, Name: "Zambian Kwacha"},
+	"ZWG": {Code: "ZWG", MinorUnits: 2, Name: "Zimbabwe Gold"},
+	"ZWL": {Code: "ZWL", MinorUnits: 2, Name: "Zimbabwe Dollar"},
+}
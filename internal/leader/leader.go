@@ -0,0 +1,120 @@
+package leader
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// releaseTimeout is the time given to release the lock when the leadership ends.
+const releaseTimeout = 5 * time.Second
+
+// Lock is held by at most one instance at a time.
+type Lock interface {
+	// TryAcquire acquires the lock if it isn't held by another instance and reports whether it did.
+	TryAcquire(context.Context) (bool, error)
+
+	// Check returns an error if the lock may have been lost.
+	Check(context.Context) error
+
+	// Release releases the lock if it's held.
+	Release(context.Context) error
+}
+
+// Local is a Lock that is always acquired, for the stores used by a single instance.
+type Local struct{}
+
+var _ Lock = Local{}
+
+func (Local) TryAcquire(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (Local) Check(context.Context) error {
+	return nil
+}
+
+func (Local) Release(context.Context) error {
+	return nil
+}
+
+// Elector runs a function on the instance holding the lock only.
+type Elector struct {
+	lock     Lock
+	interval time.Duration
+	logger   *logrus.Logger
+}
+
+// NewElector creates an Elector trying to acquire the lock and checking it's still held every interval.
+func NewElector(lock Lock, interval time.Duration, logger *logrus.Logger) *Elector {
+	return &Elector{
+		lock:     lock,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run calls fn whenever the instance becomes the leader, until the context is cancelled.
+// The context passed to fn is cancelled when the leadership is lost.
+func (e *Elector) Run(ctx context.Context, fn func(context.Context)) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		acquired, err := e.lock.TryAcquire(ctx)
+		if err != nil && ctx.Err() == nil {
+			e.logger.Errorf("error occurred while acquiring the leader lock: %s", err.Error())
+		}
+
+		if acquired {
+			e.logger.Info("became the leader")
+			e.lead(ctx, fn)
+			e.logger.Info("stopped being the leader")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead runs fn while the lock is held, then releases it.
+func (e *Elector) lead(ctx context.Context, fn func(context.Context)) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(leaderCtx)
+	}()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+check:
+	for {
+		select {
+		case <-done:
+			break check
+		case <-ticker.C:
+			if err := e.lock.Check(leaderCtx); err != nil {
+				if leaderCtx.Err() == nil {
+					e.logger.Errorf("the leader lock was lost: %s", err.Error())
+				}
+				cancel()
+				<-done
+				break check
+			}
+		}
+	}
+
+	releaseCtx, cancelRelease := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancelRelease()
+
+	if err := e.lock.Release(releaseCtx); err != nil {
+		e.logger.Errorf("error occurred while releasing the leader lock: %s", err.Error())
+	}
+}
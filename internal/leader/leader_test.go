@@ -0,0 +1,141 @@
+package leader_test
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/leader"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sharedLock is a lock shared by the instances of a test, which can be taken away from its holder.
+type sharedLock struct {
+	mu     *sync.Mutex
+	holder *string
+	name   string
+}
+
+func (l *sharedLock) TryAcquire(context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if *l.holder == "" {
+		*l.holder = l.name
+	}
+
+	return *l.holder == l.name, nil
+}
+
+func (l *sharedLock) Check(context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if *l.holder != l.name {
+		return errors.New("lost")
+	}
+
+	return nil
+}
+
+func (l *sharedLock) Release(context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if *l.holder == l.name {
+		*l.holder = ""
+	}
+
+	return nil
+}
+
+func TestElector_Run(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		holder  string
+		running = make(map[string]bool)
+	)
+
+	isRunning := func(name string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return running[name]
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "b"} {
+		name := name
+		elector := leader.NewElector(&sharedLock{mu: &mu, holder: &holder, name: name}, 10*time.Millisecond, logrus.New())
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			elector.Run(ctx, func(ctx context.Context) {
+				mu.Lock()
+				running[name] = true
+				mu.Unlock()
+
+				<-ctx.Done()
+
+				mu.Lock()
+				running[name] = false
+				mu.Unlock()
+			})
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	first := holder
+	mu.Unlock()
+
+	assert.NotEmpty(t, first)
+	assert.True(t, isRunning(first))
+	assert.Equal(t, 1, countRunning(&mu, running))
+
+	// the leader loses the lock, e.g. its DB session dies, and the other instance takes it over
+	second := "a"
+	if first == "a" {
+		second = "b"
+	}
+
+	mu.Lock()
+	holder = second
+	mu.Unlock()
+
+	time.Sleep(100 * time.Millisecond)
+
+	assert.False(t, isRunning(first))
+	assert.True(t, isRunning(second))
+
+	cancel()
+	wg.Wait()
+
+	assert.Equal(t, 0, countRunning(&mu, running))
+	assert.Empty(t, holder)
+}
+
+func countRunning(mu *sync.Mutex, running map[string]bool) int {
+	mu.Lock()
+	defer mu.Unlock()
+
+	n := 0
+	for _, r := range running {
+		if r {
+			n++
+		}
+	}
+
+	return n
+}
+
+func TestLocal(t *testing.T) {
+	acquired, err := leader.Local{}.TryAcquire(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+}
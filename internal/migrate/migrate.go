@@ -0,0 +1,394 @@
+// Package migrate applies the versioned SQL migrations and tracks the applied ones in the applied_migrations table.
+// The migrations applied to the database by golang-migrate before are adopted.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// migrationFileName is the name of a migration file like "20220207142359_init.up.sql".
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+var (
+	ErrUnknownVersion = errors.New("unknown migration version")
+	ErrDirty          = errors.New("the database was left dirty by golang-migrate")
+)
+
+// Migration is a schema change applied by its up script and reverted by its down one.
+type Migration struct {
+	Version int64
+	Name    string
+	up      string
+	down    string
+}
+
+// Status is a migration and the time it was applied at, nil if it's pending.
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Lock keeps the instances sharing the database from migrating it at the same time.
+type Lock interface {
+	// Lock waits until the lock is acquired and returns the function releasing it.
+	Lock(context.Context) (func(), error)
+}
+
+// Migrator applies the migrations to the database, each of them in its own transaction.
+type Migrator struct {
+	db         *sql.DB
+	migrations []*Migration
+	lock       Lock
+}
+
+// New creates a Migrator of the migrations in the root of fsys, every one of them must have both scripts.
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// SetLock makes the migrator hold the lock while it applies or reverts the migrations.
+func (m *Migrator) SetLock(lock Lock) {
+	m.lock = lock
+}
+
+// acquire acquires the lock, if there is one, and returns the function releasing it.
+func (m *Migrator) acquire(ctx context.Context) (func(), error) {
+	if m.lock == nil {
+		return func() {}, nil
+	}
+
+	return m.lock.Lock(ctx)
+}
+
+// load returns the migrations sorted by version.
+func load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		matches := migrationFileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version of the migration %s: %w", entry.Name(), err)
+		}
+
+		script, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migrations %s and %s have the same version", migration.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			migration.up = string(script)
+		} else {
+			migration.down = string(script)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.up == "" || migration.down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down scripts", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Up applies all the pending migrations and returns them.
+func (m *Migrator) Up(ctx context.Context) ([]*Migration, error) {
+	if len(m.migrations) == 0 {
+		return nil, nil
+	}
+
+	return m.Goto(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down reverts the last applied migration and returns it, if there is one.
+func (m *Migrator) Down(ctx context.Context) ([]*Migration, error) {
+	release, err := m.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var last int64
+	for v := range applied {
+		if v > last {
+			last = v
+		}
+	}
+
+	if last == 0 {
+		return nil, nil
+	}
+
+	migration := m.find(last)
+	if migration == nil {
+		return nil, fmt.Errorf("migration %d is applied but unknown", last)
+	}
+
+	if err = m.revert(ctx, migration); err != nil {
+		return nil, err
+	}
+
+	return []*Migration{migration}, nil
+}
+
+// Goto applies the pending migrations up to the version and reverts the applied ones after it,
+// then returns the migrations applied or reverted in the order they were. Version 0 reverts all of them.
+func (m *Migrator) Goto(ctx context.Context, version int64) ([]*Migration, error) {
+	if version != 0 && m.find(version) == nil {
+		return nil, ErrUnknownVersion
+	}
+
+	// the migrations applied by another instance in the meantime are read after the lock is acquired
+	release, err := m.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the applied migrations missing in the binary can't be reverted
+	for v := range applied {
+		if v > version && m.find(v) == nil {
+			return nil, fmt.Errorf("migration %d is applied but unknown", v)
+		}
+	}
+
+	var done []*Migration
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+			continue
+		}
+
+		if err = m.revert(ctx, migration); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok || migration.Version > version {
+			continue
+		}
+
+		if err = m.apply(ctx, migration); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Status returns the statuses of all the migrations sorted by version.
+func (m *Migrator) Status(ctx context.Context) ([]*Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := &Status{
+			Version: migration.Version,
+			Name:    migration.Name,
+		}
+
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Pending returns the number of the migrations not applied yet.
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending++
+		}
+	}
+
+	return pending, nil
+}
+
+func (m *Migrator) find(version int64) *Migration {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+
+	return nil
+}
+
+// applied returns the times the applied migrations were applied at by their versions.
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	// the statements are the same in Postgres and SQLite, so no placeholders are used by the migrator,
+	// and the existence of the table is checked by querying it
+	if _, err := m.db.ExecContext(ctx, "SELECT 1 FROM applied_migrations LIMIT 1"); err != nil {
+		if err = m.createTable(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := m.db.QueryContext(ctx, "SELECT version, applied_at FROM applied_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var (
+			version   int64
+			appliedAt time.Time
+		)
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// createTable creates the applied_migrations table and adopts the migrations up to the version
+// in the schema_migrations table of golang-migrate, if there is one, in the same transaction.
+func (m *Migrator) createTable(ctx context.Context) error {
+	var (
+		version int64
+		dirty   bool
+	)
+
+	// there is nothing to adopt if the database was never migrated by golang-migrate
+	adopt := m.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty) == nil
+	if adopt && dirty {
+		return fmt.Errorf("%w at the version %d, fix it and force the version before migrating", ErrDirty, version)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS applied_migrations (version BIGINT PRIMARY KEY NOT NULL, applied_at TIMESTAMP NOT NULL)",
+	); err != nil {
+		return err
+	}
+
+	for _, migration := range m.migrations {
+		if !adopt || migration.Version > version {
+			break
+		}
+
+		// another instance may be adopting the migrations at the same time
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf(
+			"INSERT INTO applied_migrations (version, applied_at) VALUES (%d, CURRENT_TIMESTAMP) ON CONFLICT DO NOTHING", migration.Version,
+		)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) apply(ctx context.Context, migration *Migration) error {
+	err := m.run(ctx, migration.up, fmt.Sprintf("INSERT INTO applied_migrations (version, applied_at) VALUES (%d, CURRENT_TIMESTAMP)", migration.Version))
+	if err != nil {
+		return fmt.Errorf("error occurred while applying the migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, migration *Migration) error {
+	err := m.run(ctx, migration.down, fmt.Sprintf("DELETE FROM applied_migrations WHERE version = %d", migration.Version))
+	if err != nil {
+		return fmt.Errorf("error occurred while reverting the migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return nil
+}
+
+// run executes the script and records it in the same transaction.
+func (m *Migrator) run(ctx context.Context, script, record string) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, record); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
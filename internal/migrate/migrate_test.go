@@ -0,0 +1,327 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/migrate"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/sqlitestore"
+	"github.com/tmrrwnxtsn/currency-conversion-api/migrations"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sqlitestore.Open(context.Background(), sqlitestore.URLScheme+filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"1_a.up.sql":   {Data: []byte("CREATE TABLE a (id INTEGER)")},
+		"1_a.down.sql": {Data: []byte("DROP TABLE a")},
+		"2_b.up.sql":   {Data: []byte("CREATE TABLE b (id INTEGER)")},
+		"2_b.down.sql": {Data: []byte("DROP TABLE b")},
+		"3_c.up.sql":   {Data: []byte("CREATE TABLE c (id INTEGER); INSERT INTO c VALUES (1)")},
+		"3_c.down.sql": {Data: []byte("DROP TABLE c")},
+		"README.md":    {Data: []byte("not a migration")},
+	}
+}
+
+func versions(migrations []*migrate.Migration) []int64 {
+	result := make([]int64, 0, len(migrations))
+	for _, migration := range migrations {
+		result = append(result, migration.Version)
+	}
+
+	return result
+}
+
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+
+	return n > 0
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name    string
+		fsys    fstest.MapFS
+		isValid bool
+	}{
+		{
+			name:    "valid",
+			fsys:    testMigrations(),
+			isValid: true,
+		},
+		{
+			name: "no down script",
+			fsys: fstest.MapFS{
+				"1_a.up.sql": {Data: []byte("CREATE TABLE a (id INTEGER)")},
+			},
+			isValid: false,
+		},
+		{
+			name: "same version",
+			fsys: fstest.MapFS{
+				"1_a.up.sql":   {Data: []byte("CREATE TABLE a (id INTEGER)")},
+				"1_a.down.sql": {Data: []byte("DROP TABLE a")},
+				"1_b.up.sql":   {Data: []byte("CREATE TABLE b (id INTEGER)")},
+				"1_b.down.sql": {Data: []byte("DROP TABLE b")},
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := migrate.New(testDB(t), tc.fsys)
+			if tc.isValid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestMigrator_Up(t *testing.T) {
+	db := testDB(t)
+
+	m, err := migrate.New(db, testMigrations())
+	assert.NoError(t, err)
+
+	done, err := m.Up(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, versions(done))
+	assert.True(t, tableExists(t, db, "c"))
+
+	done, err = m.Up(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, done)
+
+	pending, err := m.Pending(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, pending)
+}
+
+func TestMigrator_Down(t *testing.T) {
+	db := testDB(t)
+
+	m, err := migrate.New(db, testMigrations())
+	assert.NoError(t, err)
+
+	done, err := m.Down(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, done)
+
+	_, err = m.Up(context.Background())
+	assert.NoError(t, err)
+
+	done, err = m.Down(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3}, versions(done))
+	assert.False(t, tableExists(t, db, "c"))
+	assert.True(t, tableExists(t, db, "b"))
+
+	pending, err := m.Pending(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pending)
+}
+
+func TestMigrator_Goto(t *testing.T) {
+	db := testDB(t)
+
+	m, err := migrate.New(db, testMigrations())
+	assert.NoError(t, err)
+
+	done, err := m.Goto(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, versions(done))
+	assert.False(t, tableExists(t, db, "c"))
+
+	done, err = m.Goto(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 1}, versions(done))
+	assert.False(t, tableExists(t, db, "a"))
+
+	_, err = m.Goto(context.Background(), 4)
+	assert.ErrorIs(t, err, migrate.ErrUnknownVersion)
+}
+
+// testLock counts the times it was acquired and released.
+type testLock struct {
+	acquired int
+	released int
+	err      error
+}
+
+func (l *testLock) Lock(context.Context) (func(), error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+
+	l.acquired++
+
+	return func() { l.released++ }, nil
+}
+
+func TestMigrator_Lock(t *testing.T) {
+	db := testDB(t)
+
+	m, err := migrate.New(db, testMigrations())
+	assert.NoError(t, err)
+
+	lock := &testLock{}
+	m.SetLock(lock)
+
+	_, err = m.Up(context.Background())
+	assert.NoError(t, err)
+	_, err = m.Down(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, lock.acquired)
+	assert.Equal(t, 2, lock.released)
+
+	// nothing is migrated without the lock
+	lock.err = errors.New("lock is unavailable")
+
+	_, err = m.Up(context.Background())
+	assert.ErrorIs(t, err, lock.err)
+
+	pending, err := m.Pending(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pending)
+}
+
+func TestMigrator_Status(t *testing.T) {
+	m, err := migrate.New(testDB(t), testMigrations())
+	assert.NoError(t, err)
+
+	_, err = m.Goto(context.Background(), 1)
+	assert.NoError(t, err)
+
+	statuses, err := m.Status(context.Background())
+	assert.NoError(t, err)
+	if assert.Equal(t, 3, len(statuses)) {
+		assert.Equal(t, "a", statuses[0].Name)
+		assert.NotNil(t, statuses[0].AppliedAt)
+		assert.Equal(t, "b", statuses[1].Name)
+		assert.Nil(t, statuses[1].AppliedAt)
+	}
+}
+
+func TestMigrator_Up_Failed(t *testing.T) {
+	db := testDB(t)
+
+	fsys := testMigrations()
+	fsys["2_b.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE b (id INTEGER); INSERT INTO missing VALUES (1)")}
+
+	m, err := migrate.New(db, fsys)
+	assert.NoError(t, err)
+
+	done, err := m.Up(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, []int64{1}, versions(done))
+
+	// the failed migration is rolled back as a whole and isn't recorded
+	assert.False(t, tableExists(t, db, "b"))
+
+	pending, err := m.Pending(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, pending)
+}
+
+func TestMigrator_Up_GolangMigrate(t *testing.T) {
+	testCases := []struct {
+		name            string
+		dirty           bool
+		expectedApplied []int64
+		isValid         bool
+	}{
+		{
+			name:            "adopted",
+			expectedApplied: []int64{3},
+			isValid:         true,
+		},
+		{
+			name:    "dirty",
+			dirty:   true,
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := testDB(t)
+
+			// the database was migrated to the version 2 by golang-migrate
+			for _, query := range []string{
+				"CREATE TABLE a (id INTEGER)",
+				"CREATE TABLE b (id INTEGER)",
+				"CREATE TABLE schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)",
+			} {
+				_, err := db.Exec(query)
+				assert.NoError(t, err)
+			}
+			_, err := db.Exec("INSERT INTO schema_migrations (version, dirty) VALUES (2, ?)", tc.dirty)
+			assert.NoError(t, err)
+
+			m, err := migrate.New(db, testMigrations())
+			assert.NoError(t, err)
+
+			done, err := m.Up(context.Background())
+			if !tc.isValid {
+				assert.ErrorIs(t, err, migrate.ErrDirty)
+				assert.False(t, tableExists(t, db, "applied_migrations"))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedApplied, versions(done))
+
+			// the adopted migrations are reverted like the applied ones
+			done, err = m.Goto(context.Background(), 0)
+			assert.NoError(t, err)
+			assert.Equal(t, []int64{3, 2, 1}, versions(done))
+			assert.False(t, tableExists(t, db, "a"))
+
+			// and they aren't adopted again
+			pending, err := m.Pending(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, 3, pending)
+		})
+	}
+}
+
+func TestMigrator_SQLite(t *testing.T) {
+	m, err := migrate.New(testDB(t), migrations.SQLite())
+	assert.NoError(t, err)
+
+	_, err = m.Up(context.Background())
+	assert.NoError(t, err)
+
+	_, err = m.Goto(context.Background(), 0)
+	assert.NoError(t, err)
+
+	_, err = m.Up(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestMigrations_Postgres(t *testing.T) {
+	_, err := migrate.New(nil, migrations.Postgres())
+	assert.NoError(t, err)
+}
@@ -1,25 +1,77 @@
 package model
 
 import (
+	"errors"
 	validation "github.com/go-ozzo/ozzo-validation"
-	"github.com/go-ozzo/ozzo-validation/is"
+	"github.com/shopspring/decimal"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/currency"
 	"time"
 )
 
+var (
+	errNotPositive         = errors.New("must be greater than zero")
+	errUnsupportedCurrency = errors.New("must be a supported ISO 4217 currency code")
+)
+
 type Rate struct {
-	ID             int       `json:"id" example:"1"`
-	FirstCurrency  string    `json:"first_currency" example:"RUB"`
-	SecondCurrency string    `json:"second_currency" example:"USD"`
-	Value          float32   `json:"value" example:"75.4"`
-	LastUpdateTime time.Time `json:"last_update_time" example:"2019-11-09T21:21:46+00:00"`
+	ID             int             `json:"id" example:"1"`
+	FirstCurrency  string          `json:"first_currency" example:"RUB"`
+	SecondCurrency string          `json:"second_currency" example:"USD"`
+	Value          decimal.Decimal `json:"value" swaggertype:"string" example:"75.4"`
+	LastUpdateTime time.Time       `json:"last_update_time" example:"2019-11-09T21:21:46+00:00"`
+	Source         string          `json:"source" example:"freecurrencyapi,exchangerateapi"`
+	PinnedUntil    *time.Time      `json:"pinned_until,omitempty" example:"2019-11-10T21:21:46+00:00"` // the rate isn't updated automatically until then
 }
 
 func (r *Rate) Validate() error {
 	return validation.ValidateStruct(
 		r,
-		validation.Field(&r.FirstCurrency, validation.Required, is.CurrencyCode),
-		validation.Field(&r.SecondCurrency, validation.Required, is.CurrencyCode),
-		validation.Field(&r.Value, validation.Required, validation.Min(0.0)),
+		validation.Field(&r.FirstCurrency, validation.Required, validation.By(isSupportedCurrency)),
+		validation.Field(&r.SecondCurrency, validation.Required, validation.By(isSupportedCurrency)),
+		validation.Field(&r.Value, validation.By(isPositive)),
 		validation.Field(&r.LastUpdateTime, validation.Required, validation.Max(time.Now())),
 	)
 }
+
+// IsPinned ...
+func (r *Rate) IsPinned(t time.Time) bool {
+	return r.PinnedUntil != nil && r.PinnedUntil.After(t)
+}
+
+func isSupportedCurrency(value interface{}) error {
+	if code, ok := value.(string); !ok || !currency.IsSupported(code) {
+		return errUnsupportedCurrency
+	}
+
+	return nil
+}
+
+func isPositive(value interface{}) error {
+	if d, ok := value.(decimal.Decimal); !ok || !d.IsPositive() {
+		return errNotPositive
+	}
+
+	return nil
+}
+
+// RateFilter ...
+type RateFilter struct {
+	Currency       string // matches both the first and the second currency
+	FirstCurrency  string
+	SecondCurrency string
+	Limit          int
+	Offset         int
+}
+
+// Match ...
+func (f *RateFilter) Match(r *Rate) bool {
+	if f.Currency != "" && r.FirstCurrency != f.Currency && r.SecondCurrency != f.Currency {
+		return false
+	}
+
+	if f.FirstCurrency != "" && r.FirstCurrency != f.FirstCurrency {
+		return false
+	}
+
+	return f.SecondCurrency == "" || r.SecondCurrency == f.SecondCurrency
+}
@@ -1,6 +1,7 @@
 package model_test
 
 import (
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
 	"testing"
@@ -38,6 +39,15 @@ func TestRate_Validate(t *testing.T) {
 			},
 			isValid: false,
 		},
+		{
+			name: "unsupported first currency",
+			r: func() *model.Rate {
+				testRate := model.TestRate(t)
+				testRate.FirstCurrency = "ABC"
+				return testRate
+			},
+			isValid: false,
+		},
 		{
 			name: "empty second currency",
 			r: func() *model.Rate {
@@ -60,7 +70,7 @@ func TestRate_Validate(t *testing.T) {
 			name: "negative rate value",
 			r: func() *model.Rate {
 				testRate := model.TestRate(t)
-				testRate.Value = -1.0
+				testRate.Value = decimal.NewFromInt(-1)
 				return testRate
 			},
 			isValid: false,
@@ -86,3 +96,13 @@ func TestRate_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestRate_IsPinned(t *testing.T) {
+	r := model.TestRate(t)
+	assert.False(t, r.IsPinned(time.Now()))
+
+	pinnedUntil := time.Now().Add(time.Hour)
+	r.PinnedUntil = &pinnedUntil
+	assert.True(t, r.IsPinned(time.Now()))
+	assert.False(t, r.IsPinned(time.Now().Add(2*time.Hour)))
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// RateAuditEntry is a record of a manual change of an exchange rate.
+type RateAuditEntry struct {
+	ID             int             `json:"id" example:"1"`
+	RateID         int             `json:"rate_id" example:"1"`
+	FirstCurrency  string          `json:"first_currency" example:"RUB"`
+	SecondCurrency string          `json:"second_currency" example:"USD"`
+	Value          decimal.Decimal `json:"value" swaggertype:"string" example:"75.4"`
+	PinnedUntil    *time.Time      `json:"pinned_until,omitempty" example:"2019-11-10T21:21:46+00:00"`
+	Author         string          `json:"author" example:"j.doe"`
+	Reason         string          `json:"reason" example:"fixed by the treasury for the end of the quarter"`
+	CreatedAt      time.Time       `json:"created_at" example:"2019-11-09T21:21:46+00:00"`
+}
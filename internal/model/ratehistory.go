@@ -0,0 +1,74 @@
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// HistoryInterval is the size of the buckets the rate history is downsampled to.
+type HistoryInterval string
+
+const (
+	HistoryIntervalRaw  HistoryInterval = ""
+	HistoryIntervalHour HistoryInterval = "hour"
+	HistoryIntervalDay  HistoryInterval = "day"
+)
+
+// RatePoint is the value of an exchange rate at some point in time.
+type RatePoint struct {
+	Value  decimal.Decimal `json:"value" swaggertype:"string" example:"75.4"`
+	Time   time.Time       `json:"time" example:"2019-11-09T21:21:46+00:00"`
+	Source string          `json:"source" example:"freecurrencyapi"`
+}
+
+// ParseHistoryInterval ...
+func ParseHistoryInterval(s string) (HistoryInterval, bool) {
+	switch HistoryInterval(s) {
+	case HistoryIntervalRaw, "raw":
+		return HistoryIntervalRaw, true
+	case HistoryIntervalHour:
+		return HistoryIntervalHour, true
+	case HistoryIntervalDay:
+		return HistoryIntervalDay, true
+	default:
+		return "", false
+	}
+}
+
+// Duration returns the length of the interval bucket, zero for HistoryIntervalRaw.
+func (i HistoryInterval) Duration() time.Duration {
+	switch i {
+	case HistoryIntervalHour:
+		return time.Hour
+	case HistoryIntervalDay:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// Downsample keeps the last point of every interval bucket and moves it to the start of the bucket.
+// The points must be sorted by time.
+func Downsample(points []*RatePoint, interval HistoryInterval) []*RatePoint {
+	bucketSize := interval.Duration()
+	if bucketSize == 0 {
+		return points
+	}
+
+	result := make([]*RatePoint, 0, len(points))
+	for _, point := range points {
+		bucket := &RatePoint{
+			Value:  point.Value,
+			Time:   point.Time.UTC().Truncate(bucketSize),
+			Source: point.Source,
+		}
+
+		if last := len(result) - 1; last >= 0 && result[last].Time.Equal(bucket.Time) {
+			result[last] = bucket
+			continue
+		}
+		result = append(result, bucket)
+	}
+
+	return result
+}
@@ -0,0 +1,44 @@
+package model_test
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"testing"
+	"time"
+)
+
+func TestDownsample(t *testing.T) {
+	start := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	points := []*model.RatePoint{
+		{Value: decimal.NewFromInt(1), Time: start.Add(5 * time.Minute)},
+		{Value: decimal.NewFromInt(2), Time: start.Add(35 * time.Minute)},
+		{Value: decimal.NewFromInt(3), Time: start.Add(65 * time.Minute)},
+		{Value: decimal.NewFromInt(4), Time: start.Add(25 * time.Hour)},
+	}
+
+	assert.Equal(t, points, model.Downsample(points, model.HistoryIntervalRaw))
+
+	hourly := model.Downsample(points, model.HistoryIntervalHour)
+	assert.Equal(t, 3, len(hourly))
+	assert.True(t, decimal.NewFromInt(2).Equal(hourly[0].Value))
+	assert.Equal(t, start, hourly[0].Time)
+	assert.True(t, decimal.NewFromInt(3).Equal(hourly[1].Value))
+	assert.Equal(t, start.Add(time.Hour), hourly[1].Time)
+
+	daily := model.Downsample(points, model.HistoryIntervalDay)
+	assert.Equal(t, 2, len(daily))
+	assert.True(t, decimal.NewFromInt(3).Equal(daily[0].Value))
+	assert.Equal(t, time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), daily[0].Time)
+	assert.True(t, decimal.NewFromInt(4).Equal(daily[1].Value))
+}
+
+func TestParseHistoryInterval(t *testing.T) {
+	for _, s := range []string{"", "raw", "hour", "day"} {
+		_, ok := model.ParseHistoryInterval(s)
+		assert.True(t, ok, s)
+	}
+
+	_, ok := model.ParseHistoryInterval("week")
+	assert.False(t, ok)
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"github.com/shopspring/decimal"
 	"testing"
 	"time"
 )
@@ -11,7 +12,8 @@ func TestRate(t *testing.T) *Rate {
 	return &Rate{
 		FirstCurrency:  "USD",
 		SecondCurrency: "RUB",
-		Value:          121.41,
+		Value:          decimal.RequireFromString("121.41"),
 		LastUpdateTime: time.Now(),
+		Source:         "test",
 	}
 }
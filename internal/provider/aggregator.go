@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Strategy defines how the quotes of several providers are combined into one.
+type Strategy string
+
+const (
+	// StrategyFallback takes the quotes of the first provider that responded successfully.
+	StrategyFallback Strategy = "fallback"
+
+	// StrategyMedian takes the median of the quotes of all providers that responded successfully.
+	StrategyMedian Strategy = "median"
+
+	// StrategyWeighted takes the weighted average of the quotes of all providers that responded successfully.
+	StrategyWeighted Strategy = "weighted"
+)
+
+// AggregatorName ...
+const AggregatorName = "aggregator"
+
+var (
+	errNoProviders = errors.New("at least one rate provider is required")
+
+	_ RateProvider   = (*Aggregator)(nil)
+	_ StatusReporter = (*Aggregator)(nil)
+)
+
+// Aggregator queries several providers for the same base currency and combines their quotes.
+type Aggregator struct {
+	strategy  Strategy
+	providers []RateProvider
+	weights   map[string]float64
+}
+
+// NewAggregator creates an Aggregator. Providers are queried in the given order.
+// The weights are used by StrategyWeighted only, providers without a weight get 1.
+func NewAggregator(strategy Strategy, providers []RateProvider, weights map[string]float64) (*Aggregator, error) {
+	if len(providers) == 0 {
+		return nil, errNoProviders
+	}
+
+	switch strategy {
+	case StrategyFallback, StrategyMedian, StrategyWeighted:
+	default:
+		return nil, fmt.Errorf("unknown aggregation strategy: %s", strategy)
+	}
+
+	return &Aggregator{
+		strategy:  strategy,
+		providers: providers,
+		weights:   weights,
+	}, nil
+}
+
+func (a *Aggregator) Name() string {
+	return AggregatorName
+}
+
+// Statuses reports the statuses of the aggregated providers that track them.
+func (a *Aggregator) Statuses() []Status {
+	statuses := make([]Status, 0, len(a.providers))
+	for _, p := range a.providers {
+		if reporter, ok := p.(StatusReporter); ok {
+			statuses = append(statuses, reporter.Statuses()...)
+		}
+	}
+
+	return statuses
+}
+
+func (a *Aggregator) GetExchangeRates(ctx context.Context, baseCurrency string) (*ExchangeRates, error) {
+	if a.strategy == StrategyFallback {
+		return a.getFirst(ctx, baseCurrency)
+	}
+
+	responses, err := a.getAll(ctx, baseCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make(map[string][]Quote)
+	for _, res := range responses {
+		for currency, quote := range res.Rates {
+			quotes[currency] = append(quotes[currency], quote)
+		}
+	}
+
+	rates := make(map[string]Quote, len(quotes))
+	for currency, currencyQuotes := range quotes {
+		var value decimal.Decimal
+		if a.strategy == StrategyMedian {
+			value = median(currencyQuotes)
+		} else {
+			value = a.weightedAverage(currencyQuotes)
+		}
+
+		var sources []string
+		for _, quote := range currencyQuotes {
+			sources = append(sources, quote.Sources...)
+		}
+
+		rates[currency] = Quote{
+			Value:   value,
+			Sources: sources,
+		}
+	}
+
+	return &ExchangeRates{
+		BaseCurrency: baseCurrency,
+		Rates:        rates,
+	}, nil
+}
+
+func (a *Aggregator) getFirst(ctx context.Context, baseCurrency string) (*ExchangeRates, error) {
+	var errs []string
+	for _, p := range a.providers {
+		res, err := p.GetExchangeRates(ctx, baseCurrency)
+		if err == nil {
+			return res, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", p.Name(), err.Error()))
+	}
+
+	return nil, fmt.Errorf("all rate providers failed: %s", strings.Join(errs, "; "))
+}
+
+func (a *Aggregator) getAll(ctx context.Context, baseCurrency string) ([]*ExchangeRates, error) {
+	responses := make([]*ExchangeRates, len(a.providers))
+	errs := make([]error, len(a.providers))
+
+	var wg sync.WaitGroup
+	for i, p := range a.providers {
+		wg.Add(1)
+		go func(i int, p RateProvider) {
+			defer wg.Done()
+			responses[i], errs[i] = p.GetExchangeRates(ctx, baseCurrency)
+		}(i, p)
+	}
+	wg.Wait()
+
+	var succeeded []*ExchangeRates
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", a.providers[i].Name(), err.Error()))
+			continue
+		}
+		succeeded = append(succeeded, responses[i])
+	}
+
+	if len(succeeded) == 0 {
+		return nil, fmt.Errorf("all rate providers failed: %s", strings.Join(failed, "; "))
+	}
+
+	return succeeded, nil
+}
+
+func (a *Aggregator) weightedAverage(quotes []Quote) decimal.Decimal {
+	sum, totalWeight := decimal.Zero, decimal.Zero
+	for _, quote := range quotes {
+		weight := decimal.NewFromInt(1)
+		if len(quote.Sources) == 1 {
+			if w, ok := a.weights[quote.Sources[0]]; ok {
+				weight = decimal.NewFromFloat(w)
+			}
+		}
+		sum = sum.Add(quote.Value.Mul(weight))
+		totalWeight = totalWeight.Add(weight)
+	}
+
+	if totalWeight.IsZero() {
+		return median(quotes)
+	}
+
+	return sum.Div(totalWeight)
+}
+
+func median(quotes []Quote) decimal.Decimal {
+	values := make([]decimal.Decimal, len(quotes))
+	for i, quote := range quotes {
+		values[i] = quote.Value
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].LessThan(values[j]) })
+
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return values[middle-1].Add(values[middle]).Div(decimal.NewFromInt(2))
+	}
+
+	return values[middle]
+}
@@ -0,0 +1,111 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider"
+	"testing"
+)
+
+type stubProvider struct {
+	name  string
+	value decimal.Decimal
+	err   error
+}
+
+func (p *stubProvider) Name() string {
+	return p.name
+}
+
+func (p *stubProvider) GetExchangeRates(_ context.Context, baseCurrency string) (*provider.ExchangeRates, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return provider.NewExchangeRates(p.name, baseCurrency, map[string]decimal.Decimal{"RUB": p.value}), nil
+}
+
+func TestNewAggregator(t *testing.T) {
+	_, err := provider.NewAggregator(provider.StrategyMedian, nil, nil)
+	assert.Error(t, err)
+
+	_, err = provider.NewAggregator("unknown", []provider.RateProvider{&stubProvider{name: "a"}}, nil)
+	assert.Error(t, err)
+}
+
+func TestAggregator_GetExchangeRates(t *testing.T) {
+	failing := &stubProvider{name: "failing", err: errors.New("upstream is down")}
+	a := &stubProvider{name: "a", value: decimal.NewFromInt(70)}
+	b := &stubProvider{name: "b", value: decimal.NewFromInt(75)}
+	c := &stubProvider{name: "c", value: decimal.NewFromInt(90)}
+
+	testCases := []struct {
+		name            string
+		strategy        provider.Strategy
+		providers       []provider.RateProvider
+		weights         map[string]float64
+		expectedValue   string
+		expectedSources []string
+		isValid         bool
+	}{
+		{
+			name:            "fallback",
+			strategy:        provider.StrategyFallback,
+			providers:       []provider.RateProvider{failing, b, a},
+			expectedValue:   "75",
+			expectedSources: []string{"b"},
+			isValid:         true,
+		},
+		{
+			name:            "median of odd count",
+			strategy:        provider.StrategyMedian,
+			providers:       []provider.RateProvider{c, failing, a, b},
+			expectedValue:   "75",
+			expectedSources: []string{"c", "a", "b"},
+			isValid:         true,
+		},
+		{
+			name:            "median of even count",
+			strategy:        provider.StrategyMedian,
+			providers:       []provider.RateProvider{a, c},
+			expectedValue:   "80",
+			expectedSources: []string{"a", "c"},
+			isValid:         true,
+		},
+		{
+			name:            "weighted",
+			strategy:        provider.StrategyWeighted,
+			providers:       []provider.RateProvider{a, c},
+			weights:         map[string]float64{"a": 3},
+			expectedValue:   "75",
+			expectedSources: []string{"a", "c"},
+			isValid:         true,
+		},
+		{
+			name:      "all failed",
+			strategy:  provider.StrategyMedian,
+			providers: []provider.RateProvider{failing},
+			isValid:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			aggregator, err := provider.NewAggregator(tc.strategy, tc.providers, tc.weights)
+			assert.NoError(t, err)
+
+			res, err := aggregator.GetExchangeRates(context.Background(), "USD")
+			if !tc.isValid {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "USD", res.BaseCurrency)
+			assert.Equal(t, tc.expectedValue, res.Rates["RUB"].Value.String())
+			assert.Equal(t, tc.expectedSources, res.Rates["RUB"].Sources)
+		})
+	}
+}
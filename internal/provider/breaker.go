@@ -0,0 +1,160 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// BreakerState ...
+type BreakerState string
+
+const (
+	// BreakerClosed lets all requests through.
+	BreakerClosed BreakerState = "closed"
+
+	// BreakerOpen rejects all requests without calling the upstream.
+	BreakerOpen BreakerState = "open"
+
+	// BreakerHalfOpen lets a single trial request through to check whether the upstream has recovered.
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// ErrCircuitOpen is returned instead of calling a provider considered to be down.
+var ErrCircuitOpen = errors.New("circuit breaker of the rate provider is open")
+
+// Status ...
+type Status struct {
+	Name     string       `json:"name" example:"freecurrencyapi"`
+	State    BreakerState `json:"state" example:"closed"`
+	Failures int          `json:"failures" example:"0"` // consecutive failures
+	OpenedAt *time.Time   `json:"opened_at,omitempty" example:"2019-11-09T21:21:46+00:00"`
+}
+
+// StatusReporter is implemented by the providers that track the health of their upstreams.
+type StatusReporter interface {
+	Statuses() []Status
+}
+
+var (
+	_ RateProvider   = (*CircuitBreaker)(nil)
+	_ StatusReporter = (*CircuitBreaker)(nil)
+)
+
+// CircuitBreaker stops calling a provider after a number of consecutive failures for a while.
+type CircuitBreaker struct {
+	provider    RateProvider
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+// NewCircuitBreaker wraps the provider so that it isn't called for openTimeout after threshold consecutive failures.
+// After that a single trial request is let through, which closes the circuit if it succeeds or opens it again otherwise.
+func NewCircuitBreaker(p RateProvider, threshold int, openTimeout time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return &CircuitBreaker{
+		provider:    p,
+		threshold:   threshold,
+		openTimeout: openTimeout,
+		state:       BreakerClosed,
+	}
+}
+
+func (b *CircuitBreaker) Name() string {
+	return b.provider.Name()
+}
+
+func (b *CircuitBreaker) GetExchangeRates(ctx context.Context, baseCurrency string) (*ExchangeRates, error) {
+	if !b.allow(time.Now()) {
+		return nil, ErrCircuitOpen
+	}
+
+	res, err := b.provider.GetExchangeRates(ctx, baseCurrency)
+	b.record(ctx, err, time.Now())
+
+	return res, err
+}
+
+// State ...
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+func (b *CircuitBreaker) Statuses() []Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := Status{
+		Name:     b.provider.Name(),
+		State:    b.state,
+		Failures: b.failures,
+	}
+
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+
+	return []Status{status}
+}
+
+// allow reports whether a request can be made at t, switching an open circuit to half-open once it timed out.
+func (b *CircuitBreaker) allow(t time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if t.Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		return true
+	case BreakerHalfOpen:
+		// the trial request is in flight
+		return false
+	default:
+		return true
+	}
+}
+
+// record updates the state with the result of a request. Cancelled requests, the ones the deadline of ctx
+// was exceeded for, exceeded quotas and rate limits say nothing about the health of the upstream, so they are ignored.
+func (b *CircuitBreaker) record(ctx context.Context, err error, t time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+
+	// the deadline may also be exceeded by the timeout of the upstream, which is its failure
+	deadlineExceeded := errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil
+
+	if errors.Is(err, context.Canceled) || deadlineExceeded || errors.Is(err, ErrQuotaExceeded) || errors.Is(err, ErrRateLimited) {
+		if b.state == BreakerHalfOpen {
+			b.state = BreakerOpen
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = t
+	}
+}
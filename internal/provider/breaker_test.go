@@ -0,0 +1,98 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_GetExchangeRates(t *testing.T) {
+	down := errors.New("upstream is down")
+	p := &flakyProvider{errs: []error{down, down, down}}
+	b := provider.NewCircuitBreaker(p, 2, 50*time.Millisecond)
+	assert.Equal(t, provider.BreakerClosed, b.State())
+
+	for i := 0; i < 2; i++ {
+		_, err := b.GetExchangeRates(context.Background(), "USD")
+		assert.ErrorIs(t, err, down)
+	}
+	assert.Equal(t, provider.BreakerOpen, b.State())
+
+	_, err := b.GetExchangeRates(context.Background(), "USD")
+	assert.ErrorIs(t, err, provider.ErrCircuitOpen)
+	assert.Equal(t, 2, p.calls)
+
+	statuses := b.Statuses()
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, "flaky", statuses[0].Name)
+	assert.Equal(t, 2, statuses[0].Failures)
+	assert.NotNil(t, statuses[0].OpenedAt)
+
+	// the trial request fails, so the circuit opens again
+	time.Sleep(60 * time.Millisecond)
+	_, err = b.GetExchangeRates(context.Background(), "USD")
+	assert.ErrorIs(t, err, down)
+	assert.Equal(t, provider.BreakerOpen, b.State())
+
+	// the trial request succeeds, so the circuit closes
+	time.Sleep(60 * time.Millisecond)
+	_, err = b.GetExchangeRates(context.Background(), "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, provider.BreakerClosed, b.State())
+	assert.Equal(t, 0, b.Statuses()[0].Failures)
+	assert.Equal(t, 4, p.calls)
+}
+
+func TestCircuitBreaker_GetExchangeRates_IgnoredErrors(t *testing.T) {
+	p := &flakyProvider{errs: []error{provider.ErrQuotaExceeded, context.Canceled}}
+	b := provider.NewCircuitBreaker(p, 1, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		_, err := b.GetExchangeRates(context.Background(), "USD")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, provider.BreakerClosed, b.State())
+}
+
+func TestCircuitBreaker_GetExchangeRates_DeadlineExceeded(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// the limiter can't let the second request through before the deadline
+	limited := provider.NewRateLimited(&flakyProvider{}, 1, 1, 0)
+	b := provider.NewCircuitBreaker(limited, 1, time.Minute)
+
+	_, err := b.GetExchangeRates(ctx, "USD")
+	assert.NoError(t, err)
+
+	_, err = b.GetExchangeRates(ctx, "USD")
+	assert.ErrorIs(t, err, provider.ErrRateLimited)
+	assert.Equal(t, provider.BreakerClosed, b.State())
+
+	<-ctx.Done()
+	p := &flakyProvider{errs: []error{context.DeadlineExceeded}}
+	b = provider.NewCircuitBreaker(p, 1, time.Minute)
+
+	_, err = b.GetExchangeRates(ctx, "USD")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, provider.BreakerClosed, b.State())
+
+	// the upstream timed out before the deadline of the caller
+	p = &flakyProvider{errs: []error{context.DeadlineExceeded}}
+	b = provider.NewCircuitBreaker(p, 1, time.Minute)
+
+	_, err = b.GetExchangeRates(context.Background(), "USD")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, provider.BreakerOpen, b.State())
+}
+
+func TestAggregator_Statuses(t *testing.T) {
+	b := provider.NewCircuitBreaker(&flakyProvider{}, 1, time.Minute)
+	a, err := provider.NewAggregator(provider.StrategyFallback, []provider.RateProvider{b, &stubProvider{name: "a"}}, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []provider.Status{{Name: "flaky", State: provider.BreakerClosed}}, a.Statuses())
+}
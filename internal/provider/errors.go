@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// StatusError is returned when an upstream responds with a non-successful status code.
+type StatusError struct {
+	BaseCurrency string
+	StatusCode   int
+	Body         string
+	RetryAfter   time.Duration // value of the Retry-After header, 0 if there isn't one
+}
+
+// NewStatusError builds a StatusError from the response, reading a part of its body.
+func NewStatusError(res *http.Response, baseCurrency string) *StatusError {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+
+	return &StatusError{
+		BaseCurrency: baseCurrency,
+		StatusCode:   res.StatusCode,
+		Body:         string(body),
+		RetryAfter:   parseRetryAfter(res.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf(
+		"response for the currency %s failed with status code: %d and body: %s",
+		e.BaseCurrency, e.StatusCode, e.Body,
+	)
+}
+
+// RedactURL removes the query from the request URL in the error of an HTTP client, it may contain an API key.
+func RedactURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+			u.RawQuery = ""
+			urlErr.URL = u.String()
+		}
+	}
+
+	return err
+}
+
+// parseRetryAfter parses the value of the Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+
+	return 0
+}
+
+// IsTransient reports whether the request that failed with the error is worth retrying:
+// the upstream timed out, was unreachable, failed with a 5xx status or asked to slow down.
+func IsTransient(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,83 @@
+package exchangerateapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// Name ...
+	Name = "exchangerateapi"
+
+	// DefaultURL ...
+	DefaultURL = "https://open.er-api.com/v6/latest"
+)
+
+var _ provider.RateProvider = (*Provider)(nil)
+
+// Provider gets exchange rates from the open access endpoint of ExchangeRate-API,
+// which doesn't require an API key.
+type Provider struct {
+	url    string
+	client *http.Client
+}
+
+func New(apiURL string, timeout time.Duration) *Provider {
+	if apiURL == "" {
+		apiURL = DefaultURL
+	}
+
+	if timeout <= 0 {
+		timeout = provider.DefaultTimeout
+	}
+
+	return &Provider{
+		url:    apiURL,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *Provider) Name() string {
+	return Name
+}
+
+type latestResponse struct {
+	Result    string                     `json:"result"`
+	ErrorType string                     `json:"error-type"`
+	BaseCode  string                     `json:"base_code"`
+	Rates     map[string]decimal.Decimal `json:"rates"`
+}
+
+func (p *Provider) GetExchangeRates(ctx context.Context, baseCurrency string) (*provider.ExchangeRates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"/"+url.PathEscape(baseCurrency), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, provider.RedactURL(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode > 299 {
+		return nil, provider.NewStatusError(res, baseCurrency)
+	}
+
+	response := &latestResponse{}
+	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+		return nil, err
+	}
+
+	if response.Result != "success" {
+		return nil, fmt.Errorf("response for the currency %s failed with error: %s", baseCurrency, response.ErrorType)
+	}
+
+	return provider.NewExchangeRates(Name, response.BaseCode, response.Rates), nil
+}
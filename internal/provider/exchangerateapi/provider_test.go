@@ -0,0 +1,33 @@
+package exchangerateapi_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider/exchangerateapi"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProvider_GetExchangeRates(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/USD":
+			_, _ = w.Write([]byte(`{"result":"success","base_code":"USD","rates":{"RUB":75.4,"EUR":0.92}}`))
+		default:
+			_, _ = w.Write([]byte(`{"result":"error","error-type":"unsupported-code"}`))
+		}
+	}))
+	defer upstream.Close()
+
+	p := exchangerateapi.New(upstream.URL, 0)
+
+	res, err := p.GetExchangeRates(context.Background(), "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", res.BaseCurrency)
+	assert.Equal(t, "75.4", res.Rates["RUB"].Value.String())
+	assert.Equal(t, []string{exchangerateapi.Name}, res.Rates["RUB"].Sources)
+
+	_, err = p.GetExchangeRates(context.Background(), "dollar")
+	assert.Error(t, err)
+}
@@ -0,0 +1,84 @@
+package freecurrencyapi
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/shopspring/decimal"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// Name ...
+	Name = "freecurrencyapi"
+
+	// DefaultURL ...
+	DefaultURL = "https://freecurrencyapi.net/api/v2/latest"
+)
+
+var _ provider.RateProvider = (*Provider)(nil)
+
+type Provider struct {
+	apiKey string
+	url    string
+	client *http.Client
+}
+
+func New(apiKey, apiURL string, timeout time.Duration) *Provider {
+	if apiURL == "" {
+		apiURL = DefaultURL
+	}
+
+	if timeout <= 0 {
+		timeout = provider.DefaultTimeout
+	}
+
+	return &Provider{
+		apiKey: apiKey,
+		url:    apiURL,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *Provider) Name() string {
+	return Name
+}
+
+type latestQuery struct {
+	BaseCurrency string `json:"base_currency"`
+}
+
+type latestResponse struct {
+	Query latestQuery                `json:"query"`
+	Data  map[string]decimal.Decimal `json:"data"`
+}
+
+func (p *Provider) GetExchangeRates(ctx context.Context, baseCurrency string) (*provider.ExchangeRates, error) {
+	params := url.Values{}
+	params.Set("apikey", p.apiKey)
+	params.Set("base_currency", baseCurrency)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, provider.RedactURL(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode > 299 {
+		return nil, provider.NewStatusError(res, baseCurrency)
+	}
+
+	response := &latestResponse{}
+	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+		return nil, err
+	}
+
+	return provider.NewExchangeRates(Name, response.Query.BaseCurrency, response.Data), nil
+}
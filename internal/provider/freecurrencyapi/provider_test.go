@@ -0,0 +1,80 @@
+package freecurrencyapi_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider/freecurrencyapi"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProvider_GetExchangeRates(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("apikey") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		base := r.URL.Query().Get("base_currency")
+		_, _ = w.Write([]byte(`{"query":{"base_currency":"` + base + `"},"data":{"RUB":75.4,"EUR":0.92}}`))
+	}))
+	defer upstream.Close()
+
+	p := freecurrencyapi.New("key", upstream.URL, 0)
+
+	res, err := p.GetExchangeRates(context.Background(), "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", res.BaseCurrency)
+	assert.Equal(t, "75.4", res.Rates["RUB"].Value.String())
+	assert.Equal(t, []string{freecurrencyapi.Name}, res.Rates["RUB"].Sources)
+
+	p = freecurrencyapi.New("wrong", upstream.URL, 0)
+
+	_, err = p.GetExchangeRates(context.Background(), "USD")
+	assert.Error(t, err)
+}
+
+func TestProvider_GetExchangeRates_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	p := freecurrencyapi.New("secret", upstream.URL, 50*time.Millisecond)
+
+	_, err := p.GetExchangeRates(context.Background(), "USD")
+	if assert.Error(t, err) {
+		assert.NotContains(t, err.Error(), "secret")
+	}
+
+	p = freecurrencyapi.New("key", upstream.URL, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = p.GetExchangeRates(ctx, "USD")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestProvider_GetExchangeRates_TooManyRequests(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer upstream.Close()
+
+	p := freecurrencyapi.New("key", upstream.URL, 0)
+
+	_, err := p.GetExchangeRates(context.Background(), "USD")
+
+	var statusErr *provider.StatusError
+	assert.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusTooManyRequests, statusErr.StatusCode)
+	assert.Equal(t, 30*time.Second, statusErr.RetryAfter)
+	assert.True(t, provider.IsTransient(err))
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/time/rate"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQuotaExceeded is returned when the daily quota of a provider is used up.
+	ErrQuotaExceeded = errors.New("daily quota of the rate provider is exceeded")
+
+	// ErrRateLimited is returned when the request can't be let through by the rate limit before the context is done.
+	ErrRateLimited = errors.New("request to the rate provider is rate limited")
+)
+
+var _ RateProvider = (*RateLimited)(nil)
+
+// RateLimited limits the requests to a provider with a token bucket and a daily quota.
+type RateLimited struct {
+	provider RateProvider
+	limiter  *rate.Limiter
+	quota    int
+
+	mu   sync.Mutex
+	day  time.Time
+	used int
+}
+
+// NewRateLimited wraps the provider so that it's called at most rps times per second with bursts of burst requests
+// and at most dailyQuota times per UTC day. Non-positive rps and dailyQuota mean no limit.
+func NewRateLimited(p RateProvider, rps float64, burst, dailyQuota int) *RateLimited {
+	limit := rate.Inf
+	if rps > 0 {
+		limit = rate.Limit(rps)
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimited{
+		provider: p,
+		limiter:  rate.NewLimiter(limit, burst),
+		quota:    dailyQuota,
+	}
+}
+
+func (l *RateLimited) Name() string {
+	return l.provider.Name()
+}
+
+func (l *RateLimited) GetExchangeRates(ctx context.Context, baseCurrency string) (*ExchangeRates, error) {
+	if !l.takeQuota(time.Now()) {
+		return nil, ErrQuotaExceeded
+	}
+
+	if err := l.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrRateLimited, err)
+	}
+
+	return l.provider.GetExchangeRates(ctx, baseCurrency)
+}
+
+// takeQuota reports whether one more request fits into the quota of the day of t and counts it if so.
+func (l *RateLimited) takeQuota(t time.Time) bool {
+	if l.quota <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	day := t.UTC().Truncate(24 * time.Hour)
+	if !day.Equal(l.day) {
+		l.day = day
+		l.used = 0
+	}
+
+	if l.used >= l.quota {
+		return false
+	}
+
+	l.used++
+
+	return true
+}
@@ -0,0 +1,44 @@
+package provider_test
+
+import (
+	"context"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider"
+	"testing"
+	"time"
+)
+
+func TestRateLimited_GetExchangeRates(t *testing.T) {
+	p := provider.NewRateLimited(&stubProvider{name: "a", value: decimal.NewFromInt(70)}, 20, 1, 0)
+	assert.Equal(t, "a", p.Name())
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := p.GetExchangeRates(context.Background(), "USD")
+		assert.NoError(t, err)
+	}
+
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestRateLimited_DailyQuota(t *testing.T) {
+	limited := provider.NewRateLimited(&stubProvider{name: "a", value: decimal.NewFromInt(70)}, 0, 0, 2)
+	b := &stubProvider{name: "b", value: decimal.NewFromInt(75)}
+
+	for i := 0; i < 2; i++ {
+		_, err := limited.GetExchangeRates(context.Background(), "USD")
+		assert.NoError(t, err)
+	}
+
+	_, err := limited.GetExchangeRates(context.Background(), "USD")
+	assert.ErrorIs(t, err, provider.ErrQuotaExceeded)
+
+	aggregator, err := provider.NewAggregator(provider.StrategyFallback, []provider.RateProvider{limited, b}, nil)
+	assert.NoError(t, err)
+
+	res, err := aggregator.GetExchangeRates(context.Background(), "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, "75", res.Rates["RUB"].Value.String())
+	assert.Equal(t, []string{"b"}, res.Rates["RUB"].Sources)
+}
@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"context"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// DefaultTimeout is the timeout of a request to an upstream provider if none is configured.
+const DefaultTimeout = 10 * time.Second
+
+// Quote ...
+type Quote struct {
+	Value   decimal.Decimal
+	Sources []string // names of the providers the value was obtained from
+}
+
+// ExchangeRates ...
+type ExchangeRates struct {
+	BaseCurrency string
+	Rates        map[string]Quote
+}
+
+// RateProvider ...
+type RateProvider interface {
+	// Name ...
+	Name() string
+
+	// GetExchangeRates ...
+	GetExchangeRates(context.Context, string) (*ExchangeRates, error)
+}
+
+// NewExchangeRates builds ExchangeRates whose quotes all come from the single provider.
+func NewExchangeRates(providerName, baseCurrency string, values map[string]decimal.Decimal) *ExchangeRates {
+	rates := make(map[string]Quote, len(values))
+	for currency, value := range values {
+		rates[currency] = Quote{
+			Value:   value,
+			Sources: []string{providerName},
+		}
+	}
+
+	return &ExchangeRates{
+		BaseCurrency: baseCurrency,
+		Rates:        rates,
+	}
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+var _ RateProvider = (*Retrying)(nil)
+
+// Retrying retries the transient failures of a provider with jittered exponential backoff.
+type Retrying struct {
+	provider   RateProvider
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+// NewRetrying wraps the provider so that a transient failure is retried up to maxRetries times.
+// The n-th retry waits a random time up to baseDelay*2^n capped by maxDelay, or as long as
+// the upstream asked in Retry-After if that doesn't exceed maxDelay.
+func NewRetrying(p RateProvider, maxRetries int, baseDelay, maxDelay time.Duration) *Retrying {
+	if maxDelay < baseDelay {
+		maxDelay = baseDelay
+	}
+
+	return &Retrying{
+		provider:   p,
+		maxRetries: maxRetries,
+		baseDelay:  baseDelay,
+		maxDelay:   maxDelay,
+	}
+}
+
+func (r *Retrying) Name() string {
+	return r.provider.Name()
+}
+
+func (r *Retrying) GetExchangeRates(ctx context.Context, baseCurrency string) (*ExchangeRates, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := r.provider.GetExchangeRates(ctx, baseCurrency)
+		if err == nil || attempt >= r.maxRetries || !IsTransient(err) {
+			return res, err
+		}
+
+		delay, ok := r.delay(attempt, err)
+		if !ok {
+			return nil, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// delay returns the time to wait before the retry following the attempt failed with the error,
+// and false if the upstream asked to wait longer than the max delay.
+func (r *Retrying) delay(attempt int, err error) (time.Duration, bool) {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return statusErr.RetryAfter, statusErr.RetryAfter <= r.maxDelay
+	}
+
+	backoff := r.baseDelay
+	for i := 0; i < attempt && backoff < r.maxDelay; i++ {
+		backoff *= 2
+	}
+
+	if backoff > r.maxDelay {
+		backoff = r.maxDelay
+	}
+
+	if backoff <= 0 {
+		return 0, true
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff)) + 1), true
+}
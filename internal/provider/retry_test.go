@@ -0,0 +1,107 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// flakyProvider fails with the errors in order before responding successfully.
+type flakyProvider struct {
+	errs  []error
+	calls int
+}
+
+func (p *flakyProvider) Name() string {
+	return "flaky"
+}
+
+func (p *flakyProvider) GetExchangeRates(_ context.Context, baseCurrency string) (*provider.ExchangeRates, error) {
+	p.calls++
+	if p.calls <= len(p.errs) {
+		return nil, p.errs[p.calls-1]
+	}
+
+	return provider.NewExchangeRates(p.Name(), baseCurrency, map[string]decimal.Decimal{"RUB": decimal.NewFromInt(70)}), nil
+}
+
+func TestRetrying_GetExchangeRates(t *testing.T) {
+	unavailable := &provider.StatusError{BaseCurrency: "USD", StatusCode: http.StatusServiceUnavailable}
+	tooManyRequests := &provider.StatusError{BaseCurrency: "USD", StatusCode: http.StatusTooManyRequests, RetryAfter: 20 * time.Millisecond}
+	unauthorized := &provider.StatusError{BaseCurrency: "USD", StatusCode: http.StatusUnauthorized}
+
+	testCases := []struct {
+		name          string
+		errs          []error
+		expectedCalls int
+		isValid       bool
+	}{
+		{
+			name:          "no failures",
+			expectedCalls: 1,
+			isValid:       true,
+		},
+		{
+			name:          "transient failures",
+			errs:          []error{unavailable, tooManyRequests},
+			expectedCalls: 3,
+			isValid:       true,
+		},
+		{
+			name:          "retries exhausted",
+			errs:          []error{unavailable, unavailable, unavailable, unavailable},
+			expectedCalls: 4,
+			isValid:       false,
+		},
+		{
+			name:          "permanent failure",
+			errs:          []error{unauthorized},
+			expectedCalls: 1,
+			isValid:       false,
+		},
+		{
+			name:          "retry after exceeds max delay",
+			errs:          []error{&provider.StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour}},
+			expectedCalls: 1,
+			isValid:       false,
+		},
+		{
+			name:          "malformed response",
+			errs:          []error{errors.New("invalid character")},
+			expectedCalls: 1,
+			isValid:       false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &flakyProvider{errs: tc.errs}
+			r := provider.NewRetrying(p, 3, time.Millisecond, 50*time.Millisecond)
+
+			_, err := r.GetExchangeRates(context.Background(), "USD")
+			if tc.isValid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+			assert.Equal(t, tc.expectedCalls, p.calls)
+		})
+	}
+}
+
+func TestRetrying_GetExchangeRates_Cancelled(t *testing.T) {
+	p := &flakyProvider{errs: []error{&provider.StatusError{StatusCode: http.StatusBadGateway}}}
+	r := provider.NewRetrying(p, 3, time.Hour, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := r.GetExchangeRates(ctx, "USD")
+	assert.Error(t, err)
+	assert.Equal(t, 1, p.calls)
+}
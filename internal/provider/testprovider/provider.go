@@ -0,0 +1,53 @@
+package testprovider
+
+import (
+	"context"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/provider"
+)
+
+// Name ...
+const Name = "test"
+
+var _ provider.RateProvider = (*Provider)(nil)
+
+// Provider serves exchange rates from a fixed in-memory table, so the server
+// can be run and tested without access to the real upstream API.
+type Provider struct {
+	usdRates map[string]decimal.Decimal
+}
+
+func New() *Provider {
+	return &Provider{
+		usdRates: map[string]decimal.Decimal{
+			"USD": decimal.RequireFromString("1"),
+			"EUR": decimal.RequireFromString("0.92"),
+			"GBP": decimal.RequireFromString("0.79"),
+			"JPY": decimal.RequireFromString("149.5"),
+			"CNY": decimal.RequireFromString("7.28"),
+			"CAD": decimal.RequireFromString("1.36"),
+			"BRL": decimal.RequireFromString("4.97"),
+			"KWD": decimal.RequireFromString("0.31"),
+			"RUB": decimal.RequireFromString("75.4"),
+		},
+	}
+}
+
+func (p *Provider) Name() string {
+	return Name
+}
+
+func (p *Provider) GetExchangeRates(_ context.Context, baseCurrency string) (*provider.ExchangeRates, error) {
+	base, ok := p.usdRates[baseCurrency]
+	if !ok {
+		return nil, fmt.Errorf("base currency %s is not supported", baseCurrency)
+	}
+
+	rates := make(map[string]decimal.Decimal, len(p.usdRates))
+	for currency, value := range p.usdRates {
+		rates[currency] = value.Div(base)
+	}
+
+	return provider.NewExchangeRates(Name, baseCurrency, rates), nil
+}
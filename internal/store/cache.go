@@ -0,0 +1,9 @@
+package store
+
+import "github.com/tmrrwnxtsn/currency-conversion-api/internal/currency"
+
+// IsCacheableMiss reports whether the absence of the rate of the currencies the lookup failed with err for can be cached.
+// Only the absence of the rates of the supported currencies is, so that the caches can't grow unbounded.
+func IsCacheableMiss(err error, firstCurrency, secondCurrency string) bool {
+	return err == ErrRowNotFound && currency.IsSupported(firstCurrency) && currency.IsSupported(secondCurrency)
+}
@@ -0,0 +1,281 @@
+package cachestore
+
+import (
+	"context"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	_ store.RateRepository = (*RateRepository)(nil)
+	_ StatsReporter        = (*RateRepository)(nil)
+)
+
+// Stats ...
+type Stats struct {
+	Hits   uint64 `json:"hits" example:"1024"`
+	Misses uint64 `json:"misses" example:"16"`
+	Size   int    `json:"size" example:"12"` // number of the cached lookups
+}
+
+// StatsReporter is implemented by the cached repositories.
+type StatsReporter interface {
+	Stats() Stats
+}
+
+// entry is a cached lookup result, a nil rate means there is no such rate.
+type entry struct {
+	rate      *model.Rate
+	expiresAt time.Time
+}
+
+// RateRepository is a read-through cache of the lookups of single rates by ID and by currencies.
+// The entries are invalidated by the writes made through it and expire after the TTL,
+// so that the writes made by other instances become visible.
+type RateRepository struct {
+	repository store.RateRepository
+	ttl        time.Duration
+
+	mu         sync.RWMutex
+	byID       map[int]entry
+	byPair     map[string]entry
+	pairsByID  map[int]string
+	generation uint64 // incremented on every invalidation
+
+	hits   uint64
+	misses uint64
+}
+
+func newRateRepository(repository store.RateRepository, ttl time.Duration) *RateRepository {
+	return &RateRepository{
+		repository: repository,
+		ttl:        ttl,
+		byID:       make(map[int]entry),
+		byPair:     make(map[string]entry),
+		pairsByID:  make(map[int]string),
+	}
+}
+
+func pairKey(firstCurrency, secondCurrency string) string {
+	return firstCurrency + "-" + secondCurrency
+}
+
+func (r *RateRepository) Create(ctx context.Context, rate *model.Rate) error {
+	if err := r.repository.Create(ctx, rate); err != nil {
+		return err
+	}
+
+	r.Invalidate(rate)
+
+	return nil
+}
+
+func (r *RateRepository) Find(ctx context.Context, id int) (*model.Rate, error) {
+	r.mu.RLock()
+	e, ok := r.byID[id]
+	r.mu.RUnlock()
+
+	if ok && time.Now().Before(e.expiresAt) {
+		return r.hit(e)
+	}
+	generation := r.miss()
+
+	rate, err := r.repository.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.put(generation, rate)
+
+	return copyRate(rate), nil
+}
+
+func (r *RateRepository) FindByCurrencies(ctx context.Context, firstCurrency, secondCurrency string) (*model.Rate, error) {
+	key := pairKey(firstCurrency, secondCurrency)
+
+	r.mu.RLock()
+	e, ok := r.byPair[key]
+	r.mu.RUnlock()
+
+	if ok && time.Now().Before(e.expiresAt) {
+		return r.hit(e)
+	}
+	generation := r.miss()
+
+	rate, err := r.repository.FindByCurrencies(ctx, firstCurrency, secondCurrency)
+	if store.IsCacheableMiss(err, firstCurrency, secondCurrency) {
+		r.putMissing(generation, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.put(generation, rate)
+
+	return copyRate(rate), nil
+}
+
+// FindAll isn't cached, but it refreshes the cached lookups of the rates found.
+func (r *RateRepository) FindAll(ctx context.Context) ([]*model.Rate, error) {
+	generation := r.currentGeneration()
+
+	rates, err := r.repository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.put(generation, rates...)
+
+	return rates, nil
+}
+
+func (r *RateRepository) Update(ctx context.Context, rate *model.Rate) error {
+	if err := r.repository.Update(ctx, rate); err != nil {
+		return err
+	}
+
+	r.Invalidate(rate)
+
+	return nil
+}
+
+func (r *RateRepository) UpdateWithAudit(ctx context.Context, rate *model.Rate, entry *model.RateAuditEntry) error {
+	if err := r.repository.UpdateWithAudit(ctx, rate, entry); err != nil {
+		return err
+	}
+
+	r.Invalidate(rate)
+
+	return nil
+}
+
+func (r *RateRepository) Upsert(ctx context.Context, rate *model.Rate) error {
+	if err := r.repository.Upsert(ctx, rate); err != nil {
+		return err
+	}
+
+	r.Invalidate(rate)
+
+	return nil
+}
+
+func (r *RateRepository) UpdateMany(ctx context.Context, rates []*model.Rate) error {
+	if err := r.repository.UpdateMany(ctx, rates); err != nil {
+		return err
+	}
+
+	r.Invalidate(rates...)
+
+	return nil
+}
+
+func (r *RateRepository) Delete(ctx context.Context, id int) error {
+	if err := r.repository.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	r.Invalidate(&model.Rate{ID: id})
+
+	return nil
+}
+
+func (r *RateRepository) List(ctx context.Context, filter *model.RateFilter) ([]*model.Rate, int, error) {
+	return r.repository.List(ctx, filter)
+}
+
+func (r *RateRepository) Stats() Stats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return Stats{
+		Hits:   atomic.LoadUint64(&r.hits),
+		Misses: atomic.LoadUint64(&r.misses),
+		Size:   len(r.byID) + len(r.byPair),
+	}
+}
+
+func (r *RateRepository) hit(e entry) (*model.Rate, error) {
+	atomic.AddUint64(&r.hits, 1)
+
+	if e.rate == nil {
+		return nil, store.ErrRowNotFound
+	}
+
+	return copyRate(e.rate), nil
+}
+
+// miss counts a cache miss and returns the generation the rate is going to be loaded in.
+func (r *RateRepository) miss() uint64 {
+	atomic.AddUint64(&r.misses, 1)
+	return r.currentGeneration()
+}
+
+func (r *RateRepository) currentGeneration() uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.generation
+}
+
+// put caches the rates loaded in the generation for both lookups. Nothing is cached if there was an invalidation
+// after the rates had started to load, as they may be outdated.
+func (r *RateRepository) put(generation uint64, rates ...*model.Rate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.generation != generation {
+		return
+	}
+
+	expiresAt := time.Now().Add(r.ttl)
+	for _, rate := range rates {
+		e := entry{
+			rate:      copyRate(rate),
+			expiresAt: expiresAt,
+		}
+		key := pairKey(rate.FirstCurrency, rate.SecondCurrency)
+
+		r.byID[rate.ID] = e
+		r.byPair[key] = e
+		r.pairsByID[rate.ID] = key
+	}
+}
+
+// putMissing caches the absence of the rate of the pair found out in the generation.
+func (r *RateRepository) putMissing(generation uint64, key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.generation == generation {
+		r.byPair[key] = entry{expiresAt: time.Now().Add(r.ttl)}
+	}
+}
+
+// Invalidate removes the cached lookups of the rates, both by the current and the former currencies.
+// Only the IDs and currencies of the rates are used.
+func (r *RateRepository) Invalidate(rates ...*model.Rate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	for _, rate := range rates {
+		delete(r.byID, rate.ID)
+		if key, ok := r.pairsByID[rate.ID]; ok {
+			delete(r.byPair, key)
+			delete(r.pairsByID, rate.ID)
+		}
+
+		if rate.FirstCurrency != "" {
+			delete(r.byPair, pairKey(rate.FirstCurrency, rate.SecondCurrency))
+		}
+	}
+}
+
+// copyRate prevents the callers from modifying the cached rates.
+func copyRate(rate *model.Rate) *model.Rate {
+	c := *rate
+	return &c
+}
@@ -0,0 +1,121 @@
+package cachestore_test
+
+import (
+	"context"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/cachestore"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/teststore"
+	"testing"
+	"time"
+)
+
+func TestRateRepository_FindByCurrencies(t *testing.T) {
+	inner := teststore.New()
+	r := model.TestRate(t)
+	_ = inner.Rate().Create(context.Background(), r)
+
+	st := cachestore.New(inner, time.Minute)
+	assert.NoError(t, st.Warm(context.Background()))
+
+	for i := 0; i < 3; i++ {
+		rate, err := st.Rate().FindByCurrencies(context.Background(), "USD", "RUB")
+		assert.NoError(t, err)
+		assert.Equal(t, r.ID, rate.ID)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := st.Rate().FindByCurrencies(context.Background(), "RUB", "USD")
+		assert.EqualError(t, err, store.ErrRowNotFound.Error())
+	}
+
+	stats := st.Rate().(cachestore.StatsReporter).Stats()
+	assert.Equal(t, uint64(4), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+}
+
+func TestRateRepository_Find(t *testing.T) {
+	inner := teststore.New()
+	r := model.TestRate(t)
+	_ = inner.Rate().Create(context.Background(), r)
+
+	st := cachestore.New(inner, time.Minute)
+
+	rate, err := st.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+
+	// the cached rate can't be modified by the callers
+	rate.Value = decimal.NewFromInt(1)
+
+	rate, err = st.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "121.41", rate.Value.String())
+
+	stats := st.Rate().(cachestore.StatsReporter).Stats()
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+}
+
+func TestRateRepository_Invalidation(t *testing.T) {
+	inner := teststore.New()
+	st := cachestore.New(inner, time.Minute)
+
+	_, err := st.Rate().FindByCurrencies(context.Background(), "USD", "RUB")
+	assert.EqualError(t, err, store.ErrRowNotFound.Error())
+
+	r := model.TestRate(t)
+	assert.NoError(t, st.Rate().Create(context.Background(), r))
+
+	rate, err := st.Rate().FindByCurrencies(context.Background(), "USD", "RUB")
+	assert.NoError(t, err)
+	assert.Equal(t, "121.41", rate.Value.String())
+
+	rUpd := *r
+	rUpd.Value = decimal.RequireFromString("80.5")
+	assert.NoError(t, st.Rate().Update(context.Background(), &rUpd))
+
+	rate, err = st.Rate().FindByCurrencies(context.Background(), "USD", "RUB")
+	assert.NoError(t, err)
+	assert.Equal(t, "80.5", rate.Value.String())
+
+	rUpd.Value = decimal.RequireFromString("81")
+	assert.NoError(t, st.Rate().UpdateMany(context.Background(), []*model.Rate{&rUpd}))
+
+	rate, err = st.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "81", rate.Value.String())
+
+	assert.NoError(t, st.Rate().Delete(context.Background(), r.ID))
+
+	_, err = st.Rate().Find(context.Background(), r.ID)
+	assert.EqualError(t, err, store.ErrRowNotFound.Error())
+
+	_, err = st.Rate().FindByCurrencies(context.Background(), "USD", "RUB")
+	assert.EqualError(t, err, store.ErrRowNotFound.Error())
+}
+
+func TestRateRepository_TTL(t *testing.T) {
+	inner := teststore.New()
+	r := model.TestRate(t)
+	_ = inner.Rate().Create(context.Background(), r)
+
+	st := cachestore.New(inner, 20*time.Millisecond)
+	assert.NoError(t, st.Warm(context.Background()))
+
+	// written bypassing the cache, e.g. by another instance
+	rUpd := *r
+	rUpd.Value = decimal.RequireFromString("80.5")
+	_ = inner.Rate().Update(context.Background(), &rUpd)
+
+	rate, err := st.Rate().FindByCurrencies(context.Background(), "USD", "RUB")
+	assert.NoError(t, err)
+	assert.Equal(t, "121.41", rate.Value.String())
+
+	time.Sleep(30 * time.Millisecond)
+
+	rate, err = st.Rate().FindByCurrencies(context.Background(), "USD", "RUB")
+	assert.NoError(t, err)
+	assert.Equal(t, "80.5", rate.Value.String())
+}
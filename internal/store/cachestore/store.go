@@ -0,0 +1,47 @@
+package cachestore
+
+import (
+	"context"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"time"
+)
+
+var _ store.Store = (*Store)(nil)
+
+// Store caches the rates of the underlying store in memory, the rest of its repositories are used as is.
+type Store struct {
+	store          store.Store
+	rateRepository *RateRepository
+}
+
+// New creates a Store caching the rates of the underlying store for ttl.
+func New(s store.Store, ttl time.Duration) *Store {
+	return &Store{
+		store:          s,
+		rateRepository: newRateRepository(s.Rate(), ttl),
+	}
+}
+
+// Warm loads all the rates of the underlying store into the cache.
+func (s *Store) Warm(ctx context.Context) error {
+	_, err := s.rateRepository.FindAll(ctx)
+	return err
+}
+
+// Invalidate removes the cached lookups of the rates, e.g. the ones written by another instance.
+func (s *Store) Invalidate(rates ...*model.Rate) {
+	s.rateRepository.Invalidate(rates...)
+}
+
+func (s *Store) Rate() store.RateRepository {
+	return s.rateRepository
+}
+
+func (s *Store) RateHistory() store.RateHistoryRepository {
+	return s.store.RateHistory()
+}
+
+func (s *Store) RateAudit() store.RateAuditRepository {
+	return s.store.RateAudit()
+}
@@ -5,4 +5,10 @@ import "errors"
 var (
 	// ErrRowNotFound ...
 	ErrRowNotFound = errors.New("row not found")
+
+	// ErrDuplicate is returned when there already is a rate of the currencies.
+	ErrDuplicate = errors.New("row already exists")
+
+	// ErrInvalidRow is returned when the row violates a check constraint of the database.
+	ErrInvalidRow = errors.New("row violates a check constraint")
 )
@@ -0,0 +1,227 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"time"
+)
+
+var _ store.RateRepository = (*RateRepository)(nil)
+
+// missing is cached when there is no rate of the pair.
+const missing = "null"
+
+// RateRepository is a read-through cache of the lookups of single rates by ID and by currencies.
+// The writes delete the cached lookups of the rates written and publish them to the InvalidationChannel.
+type RateRepository struct {
+	repository store.RateRepository
+	client     *redis.Client
+	ttl        time.Duration
+}
+
+func idKey(id int) string {
+	return fmt.Sprintf("rate:id:%d", id)
+}
+
+func pairKey(firstCurrency, secondCurrency string) string {
+	return fmt.Sprintf("rate:pair:%s-%s", firstCurrency, secondCurrency)
+}
+
+func (r *RateRepository) Create(ctx context.Context, rate *model.Rate) error {
+	if err := r.repository.Create(ctx, rate); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, rate)
+
+	return nil
+}
+
+func (r *RateRepository) Find(ctx context.Context, id int) (*model.Rate, error) {
+	if rate, ok := r.get(ctx, idKey(id)); ok {
+		if rate == nil {
+			return nil, store.ErrRowNotFound
+		}
+		return rate, nil
+	}
+
+	rate, err := r.repository.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.put(ctx, rate)
+
+	return rate, nil
+}
+
+func (r *RateRepository) FindByCurrencies(ctx context.Context, firstCurrency, secondCurrency string) (*model.Rate, error) {
+	key := pairKey(firstCurrency, secondCurrency)
+	if rate, ok := r.get(ctx, key); ok {
+		if rate == nil {
+			return nil, store.ErrRowNotFound
+		}
+		return rate, nil
+	}
+
+	rate, err := r.repository.FindByCurrencies(ctx, firstCurrency, secondCurrency)
+	if store.IsCacheableMiss(err, firstCurrency, secondCurrency) {
+		r.client.SetNX(ctx, key, missing, r.ttl)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.put(ctx, rate)
+
+	return rate, nil
+}
+
+func (r *RateRepository) FindAll(ctx context.Context) ([]*model.Rate, error) {
+	return r.repository.FindAll(ctx)
+}
+
+func (r *RateRepository) Update(ctx context.Context, rate *model.Rate) error {
+	if err := r.repository.Update(ctx, rate); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, rate)
+
+	return nil
+}
+
+func (r *RateRepository) UpdateWithAudit(ctx context.Context, rate *model.Rate, entry *model.RateAuditEntry) error {
+	if err := r.repository.UpdateWithAudit(ctx, rate, entry); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, rate)
+
+	return nil
+}
+
+func (r *RateRepository) Upsert(ctx context.Context, rate *model.Rate) error {
+	if err := r.repository.Upsert(ctx, rate); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, rate)
+
+	return nil
+}
+
+func (r *RateRepository) UpdateMany(ctx context.Context, rates []*model.Rate) error {
+	if err := r.repository.UpdateMany(ctx, rates); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, rates...)
+
+	return nil
+}
+
+func (r *RateRepository) Delete(ctx context.Context, id int) error {
+	if err := r.repository.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, &model.Rate{ID: id})
+
+	return nil
+}
+
+func (r *RateRepository) List(ctx context.Context, filter *model.RateFilter) ([]*model.Rate, int, error) {
+	return r.repository.List(ctx, filter)
+}
+
+// get returns the cached lookup and whether there is one, a nil rate means there is no such rate.
+// Redis being unavailable is treated as a cache miss.
+func (r *RateRepository) get(ctx context.Context, key string) (*model.Rate, bool) {
+	data, err := r.client.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, false
+	}
+
+	if string(data) == missing {
+		return nil, true
+	}
+
+	rate := &model.Rate{}
+	if err = json.Unmarshal(data, rate); err != nil {
+		return nil, false
+	}
+
+	return rate, true
+}
+
+// put caches the rate for both lookups. A rate invalidated concurrently may be cached until it expires,
+// so the TTL should be short.
+func (r *RateRepository) put(ctx context.Context, rate *model.Rate) {
+	data, err := json.Marshal(rate)
+	if err != nil {
+		return
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, idKey(rate.ID), data, r.ttl)
+	pipe.Set(ctx, pairKey(rate.FirstCurrency, rate.SecondCurrency), data, r.ttl)
+	_, _ = pipe.Exec(ctx)
+}
+
+// invalidate deletes the cached lookups of the rates, both by the current and the former currencies,
+// and publishes the rates to the other instances. The rates are already written at this point,
+// so if Redis is unavailable they stay cached until they expire.
+func (r *RateRepository) invalidate(ctx context.Context, rates ...*model.Rate) {
+	keys := make([]string, 0, len(rates))
+	for _, rate := range rates {
+		keys = append(keys, idKey(rate.ID))
+	}
+
+	cached, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return
+	}
+
+	invalidated := make([]*model.Rate, 0, len(rates))
+	for i, rate := range rates {
+		pairs := make([]*model.Rate, 0, 2)
+		if rate.FirstCurrency != "" {
+			pairs = append(pairs, &model.Rate{ID: rate.ID, FirstCurrency: rate.FirstCurrency, SecondCurrency: rate.SecondCurrency})
+		}
+
+		if data, ok := cached[i].(string); ok {
+			former := &model.Rate{}
+			if err = json.Unmarshal([]byte(data), former); err == nil &&
+				(former.FirstCurrency != rate.FirstCurrency || former.SecondCurrency != rate.SecondCurrency) {
+				pairs = append(pairs, &model.Rate{ID: rate.ID, FirstCurrency: former.FirstCurrency, SecondCurrency: former.SecondCurrency})
+			}
+		}
+
+		if len(pairs) == 0 {
+			pairs = append(pairs, &model.Rate{ID: rate.ID})
+		}
+
+		for _, pair := range pairs {
+			if pair.FirstCurrency != "" {
+				keys = append(keys, pairKey(pair.FirstCurrency, pair.SecondCurrency))
+			}
+		}
+		invalidated = append(invalidated, pairs...)
+	}
+
+	if err = r.client.Del(ctx, keys...).Err(); err != nil {
+		return
+	}
+
+	message, err := json.Marshal(invalidated)
+	if err != nil {
+		return
+	}
+
+	r.client.Publish(ctx, InvalidationChannel, message)
+}
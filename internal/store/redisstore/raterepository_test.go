@@ -0,0 +1,126 @@
+package redisstore_test
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/redisstore"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/teststore"
+	"testing"
+	"time"
+)
+
+func testClient(t *testing.T) *redis.Client {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	return client
+}
+
+func TestRateRepository_FindByCurrencies(t *testing.T) {
+	inner := teststore.New()
+	r := model.TestRate(t)
+	_ = inner.Rate().Create(context.Background(), r)
+
+	client := testClient(t)
+	st := redisstore.New(inner, client, time.Minute)
+	// another instance sharing the cache
+	other := redisstore.New(inner, client, time.Minute)
+
+	rate, err := st.Rate().FindByCurrencies(context.Background(), "USD", "RUB")
+	assert.NoError(t, err)
+	assert.Equal(t, r.ID, rate.ID)
+
+	// written bypassing the cache, so the cached rate is returned
+	rUpd := *r
+	rUpd.Value = decimal.RequireFromString("80.5")
+	_ = inner.Rate().Update(context.Background(), &rUpd)
+
+	for _, s := range []*redisstore.Store{st, other} {
+		rate, err = s.Rate().FindByCurrencies(context.Background(), "USD", "RUB")
+		assert.NoError(t, err)
+		assert.Equal(t, "121.41", rate.Value.String())
+		assert.True(t, r.LastUpdateTime.Equal(rate.LastUpdateTime))
+
+		rate, err = s.Rate().Find(context.Background(), r.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "121.41", rate.Value.String())
+	}
+
+	_, err = st.Rate().FindByCurrencies(context.Background(), "RUB", "USD")
+	assert.EqualError(t, err, store.ErrRowNotFound.Error())
+
+	_ = inner.Rate().Create(context.Background(), &model.Rate{
+		FirstCurrency:  "RUB",
+		SecondCurrency: "USD",
+		Value:          decimal.RequireFromString("0.0125"),
+		LastUpdateTime: time.Now(),
+	})
+
+	_, err = other.Rate().FindByCurrencies(context.Background(), "RUB", "USD")
+	assert.EqualError(t, err, store.ErrRowNotFound.Error())
+}
+
+func TestRateRepository_Invalidation(t *testing.T) {
+	inner := teststore.New()
+	client := testClient(t)
+	st := redisstore.New(inner, client, time.Minute)
+	other := redisstore.New(inner, client, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	invalidated := make(chan []*model.Rate, 3)
+	subscribed := make(chan error, 1)
+	go func() {
+		subscribed <- redisstore.Subscribe(ctx, client, func(rates ...*model.Rate) { invalidated <- rates })
+	}()
+
+	_, err := other.Rate().FindByCurrencies(context.Background(), "USD", "RUB")
+	assert.EqualError(t, err, store.ErrRowNotFound.Error())
+
+	// give the subscription time to be established
+	time.Sleep(50 * time.Millisecond)
+
+	r := model.TestRate(t)
+	assert.NoError(t, st.Rate().Create(context.Background(), r))
+
+	rate, err := other.Rate().FindByCurrencies(context.Background(), "USD", "RUB")
+	assert.NoError(t, err)
+	assert.Equal(t, "121.41", rate.Value.String())
+
+	rUpd := *r
+	rUpd.Value = decimal.RequireFromString("80.5")
+	assert.NoError(t, st.Rate().UpdateMany(context.Background(), []*model.Rate{&rUpd}))
+
+	rate, err = other.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "80.5", rate.Value.String())
+
+	assert.NoError(t, st.Rate().Delete(context.Background(), r.ID))
+
+	_, err = other.Rate().FindByCurrencies(context.Background(), "USD", "RUB")
+	assert.EqualError(t, err, store.ErrRowNotFound.Error())
+
+	for i := 0; i < 3; i++ {
+		select {
+		case rates := <-invalidated:
+			assert.Equal(t, r.ID, rates[0].ID)
+			assert.Equal(t, "USD", rates[0].FirstCurrency)
+			assert.Equal(t, "RUB", rates[0].SecondCurrency)
+		case <-time.After(time.Second):
+			t.Fatal("invalidation wasn't published")
+		}
+	}
+
+	cancel()
+	assert.NoError(t, <-subscribed)
+}
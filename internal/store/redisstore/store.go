@@ -0,0 +1,76 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redis/v8"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"time"
+)
+
+// InvalidationChannel is the pub/sub channel the invalidated rates are published to.
+const InvalidationChannel = "rate:invalidation"
+
+var _ store.Store = (*Store)(nil)
+
+// Store caches the rates of the underlying store in Redis, so that the cache is shared by all the instances.
+// The rest of its repositories are used as is.
+type Store struct {
+	store          store.Store
+	rateRepository *RateRepository
+}
+
+// New creates a Store caching the rates of the underlying store for ttl.
+func New(s store.Store, client *redis.Client, ttl time.Duration) *Store {
+	return &Store{
+		store: s,
+		rateRepository: &RateRepository{
+			repository: s.Rate(),
+			client:     client,
+			ttl:        ttl,
+		},
+	}
+}
+
+func (s *Store) Rate() store.RateRepository {
+	return s.rateRepository
+}
+
+func (s *Store) RateHistory() store.RateHistoryRepository {
+	return s.store.RateHistory()
+}
+
+func (s *Store) RateAudit() store.RateAuditRepository {
+	return s.store.RateAudit()
+}
+
+// Subscribe calls the handler with the rates invalidated by any instance until the context is cancelled.
+// The rates passed to the handler have only their IDs and currencies set.
+func Subscribe(ctx context.Context, client *redis.Client, handler func(...*model.Rate)) error {
+	pubsub := client.Subscribe(ctx, InvalidationChannel)
+	defer pubsub.Close()
+
+	// wait for the confirmation so that no invalidation published after the return of Subscribe is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+
+			var rates []*model.Rate
+			if err := json.Unmarshal([]byte(msg.Payload), &rates); err != nil {
+				continue
+			}
+			handler(rates...)
+		}
+	}
+}
@@ -1,21 +1,61 @@
 package store
 
-import "github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+import (
+	"context"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"time"
+)
 
 // RateRepository ...
 type RateRepository interface {
 	// Create ...
-	Create(*model.Rate) error
+	Create(context.Context, *model.Rate) error
 
 	// Find ...
-	Find(int) (*model.Rate, error)
+	Find(context.Context, int) (*model.Rate, error)
 
 	// FindByCurrencies ...
-	FindByCurrencies(string, string) (*model.Rate, error)
+	FindByCurrencies(context.Context, string, string) (*model.Rate, error)
 
 	// FindAll ...
-	FindAll() ([]*model.Rate, error)
+	FindAll(context.Context) ([]*model.Rate, error)
 
 	// Update ...
-	Update(*model.Rate) error
+	Update(context.Context, *model.Rate) error
+
+	// UpdateWithAudit updates the rate and records the audit entry of the change atomically, then sets the ID of the entry.
+	UpdateWithAudit(context.Context, *model.Rate, *model.RateAuditEntry) error
+
+	// Upsert atomically creates the rate or updates the one of the same currencies, then sets the ID of the rate.
+	Upsert(context.Context, *model.Rate) error
+
+	// UpdateMany updates the rates atomically, the ones deleted or pinned in the meantime are skipped.
+	UpdateMany(context.Context, []*model.Rate) error
+
+	// Delete ...
+	Delete(context.Context, int) error
+
+	// List returns a page of the rates matching the filter and the total number of them.
+	List(context.Context, *model.RateFilter) ([]*model.Rate, int, error)
+}
+
+// RateAuditRepository ...
+type RateAuditRepository interface {
+	// Create ...
+	Create(context.Context, *model.RateAuditEntry) error
+
+	// FindByRate ...
+	FindByRate(context.Context, int) ([]*model.RateAuditEntry, error)
+}
+
+// RateHistoryRepository ...
+type RateHistoryRepository interface {
+	// Append ...
+	Append(context.Context, *model.Rate) error
+
+	// FindRange ...
+	FindRange(context.Context, string, string, time.Time, time.Time, model.HistoryInterval) ([]*model.RatePoint, error)
+
+	// FindAsOf ...
+	FindAsOf(context.Context, string, string, time.Time) (*model.RatePoint, error)
 }
@@ -0,0 +1,25 @@
+package sqlitestore
+
+import (
+	"errors"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// storeError maps the constraint violations to the store errors.
+func storeError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+		return store.ErrDuplicate
+	case sqlite3.SQLITE_CONSTRAINT_CHECK:
+		return store.ErrInvalidRow
+	default:
+		return err
+	}
+}
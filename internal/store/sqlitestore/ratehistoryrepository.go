@@ -0,0 +1,79 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"time"
+)
+
+var _ store.RateHistoryRepository = (*RateHistoryRepository)(nil)
+
+type RateHistoryRepository struct {
+	store *Store
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func (r *RateHistoryRepository) Append(ctx context.Context, rate *model.Rate) error {
+	return appendRateHistory(ctx, r.store.db, rate)
+}
+
+func appendRateHistory(ctx context.Context, e execer, rate *model.Rate) error {
+	_, err := e.ExecContext(ctx,
+		"INSERT INTO rate_history (first_currency, second_currency, value, source, recorded_at) VALUES (?, ?, ?, ?, ?)",
+		rate.FirstCurrency, rate.SecondCurrency, rate.Value, rate.Source, utc(rate.LastUpdateTime),
+	)
+	return err
+}
+
+// FindRange downsamples the points in Go, SQLite has no date_trunc.
+func (r *RateHistoryRepository) FindRange(ctx context.Context, firstCurrency, secondCurrency string, start, end time.Time, interval model.HistoryInterval) ([]*model.RatePoint, error) {
+	rows, err := r.store.db.QueryContext(ctx,
+		`SELECT value, recorded_at, source FROM rate_history
+		WHERE first_currency = ? AND second_currency = ? AND recorded_at BETWEEN ? AND ?
+		ORDER BY recorded_at, id`,
+		firstCurrency, secondCurrency, utc(start), utc(end),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := make([]*model.RatePoint, 0)
+	for rows.Next() {
+		point := &model.RatePoint{}
+		if err = rows.Scan(&point.Value, &point.Time, &point.Source); err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return model.Downsample(points, interval), nil
+}
+
+func (r *RateHistoryRepository) FindAsOf(ctx context.Context, firstCurrency, secondCurrency string, t time.Time) (*model.RatePoint, error) {
+	point := &model.RatePoint{}
+	if err := r.store.db.QueryRowContext(ctx,
+		`SELECT value, recorded_at, source FROM rate_history
+		WHERE first_currency = ? AND second_currency = ? AND recorded_at <= ?
+		ORDER BY recorded_at DESC, id DESC LIMIT 1`,
+		firstCurrency, secondCurrency, utc(t),
+	).Scan(&point.Value, &point.Time, &point.Source); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRowNotFound
+		}
+
+		return nil, err
+	}
+
+	return point, nil
+}
@@ -0,0 +1,297 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"time"
+)
+
+var _ store.RateRepository = (*RateRepository)(nil)
+
+type RateRepository struct {
+	store *Store
+}
+
+func (r *RateRepository) Create(ctx context.Context, rate *model.Rate) error {
+	if err := rate.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := r.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = tx.QueryRowContext(ctx,
+		"INSERT INTO rate (first_currency, second_currency, value, last_update_time, source, pinned_until) VALUES (?, ?, ?, ?, ?, ?) RETURNING id",
+		rate.FirstCurrency, rate.SecondCurrency, rate.Value, utc(rate.LastUpdateTime), rate.Source, nullUTC(rate.PinnedUntil),
+	).Scan(&rate.ID); err != nil {
+		return storeError(err)
+	}
+
+	if err = appendRateHistory(ctx, tx, rate); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RateRepository) Find(ctx context.Context, id int) (*model.Rate, error) {
+	rate := &model.Rate{}
+	if err := r.store.db.QueryRowContext(ctx,
+		"SELECT id, first_currency, second_currency, value, last_update_time, source, pinned_until FROM rate WHERE id = ?",
+		id,
+	).Scan(&rate.ID, &rate.FirstCurrency, &rate.SecondCurrency, &rate.Value, &rate.LastUpdateTime, &rate.Source, &rate.PinnedUntil); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRowNotFound
+		}
+
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+func (r *RateRepository) FindByCurrencies(ctx context.Context, firstCurrency, secondCurrency string) (*model.Rate, error) {
+	rate := &model.Rate{}
+	if err := r.store.db.QueryRowContext(ctx,
+		"SELECT id, first_currency, second_currency, value, last_update_time, source, pinned_until FROM rate WHERE first_currency = ? AND second_currency = ?",
+		firstCurrency, secondCurrency,
+	).Scan(&rate.ID, &rate.FirstCurrency, &rate.SecondCurrency, &rate.Value, &rate.LastUpdateTime, &rate.Source, &rate.PinnedUntil); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRowNotFound
+		}
+
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+func (r *RateRepository) FindAll(ctx context.Context) ([]*model.Rate, error) {
+	var rates []*model.Rate
+
+	rows, err := r.store.db.QueryContext(ctx, "SELECT id, first_currency, second_currency, value, last_update_time, source, pinned_until FROM rate")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rate := &model.Rate{}
+		if err = rows.Scan(&rate.ID, &rate.FirstCurrency, &rate.SecondCurrency, &rate.Value, &rate.LastUpdateTime, &rate.Source, &rate.PinnedUntil); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (r *RateRepository) List(ctx context.Context, filter *model.RateFilter) ([]*model.Rate, int, error) {
+	where := "WHERE (?1 = '' OR first_currency = ?1 OR second_currency = ?1) AND (?2 = '' OR first_currency = ?2) AND (?3 = '' OR second_currency = ?3)"
+	args := []interface{}{filter.Currency, filter.FirstCurrency, filter.SecondCurrency}
+
+	var total int
+	if err := r.store.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM rate "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// a negative LIMIT means no limit
+	limit := -1
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+
+	rows, err := r.store.db.QueryContext(ctx,
+		fmt.Sprintf("SELECT id, first_currency, second_currency, value, last_update_time, source, pinned_until FROM rate %s ORDER BY id LIMIT ?4 OFFSET ?5", where),
+		append(args, limit, filter.Offset)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	rates := make([]*model.Rate, 0)
+	for rows.Next() {
+		rate := &model.Rate{}
+		if err = rows.Scan(&rate.ID, &rate.FirstCurrency, &rate.SecondCurrency, &rate.Value, &rate.LastUpdateTime, &rate.Source, &rate.PinnedUntil); err != nil {
+			return nil, 0, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return rates, total, nil
+}
+
+func (r *RateRepository) Update(ctx context.Context, rate *model.Rate) error {
+	if err := rate.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := r.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = updateRate(ctx, tx, rate); err != nil {
+		return err
+	}
+
+	if err = appendRateHistory(ctx, tx, rate); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RateRepository) UpdateWithAudit(ctx context.Context, rate *model.Rate, entry *model.RateAuditEntry) error {
+	if err := rate.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := r.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = updateRate(ctx, tx, rate); err != nil {
+		return err
+	}
+
+	if err = appendRateHistory(ctx, tx, rate); err != nil {
+		return err
+	}
+
+	if err = createRateAudit(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RateRepository) Upsert(ctx context.Context, rate *model.Rate) error {
+	if err := rate.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := r.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = tx.QueryRowContext(ctx,
+		`INSERT INTO rate (first_currency, second_currency, value, last_update_time, source, pinned_until) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (first_currency, second_currency) DO UPDATE
+		SET value = excluded.value, last_update_time = excluded.last_update_time, source = excluded.source, pinned_until = excluded.pinned_until
+		RETURNING id`,
+		rate.FirstCurrency, rate.SecondCurrency, rate.Value, utc(rate.LastUpdateTime), rate.Source, nullUTC(rate.PinnedUntil),
+	).Scan(&rate.ID); err != nil {
+		return storeError(err)
+	}
+
+	if err = appendRateHistory(ctx, tx, rate); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RateRepository) UpdateMany(ctx context.Context, rates []*model.Rate) error {
+	for _, rate := range rates {
+		if err := rate.Validate(); err != nil {
+			return err
+		}
+	}
+
+	tx, err := r.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, rate := range rates {
+		if err = updateUnpinnedRate(ctx, tx, rate, now); err != nil {
+			if err == store.ErrRowNotFound {
+				continue
+			}
+
+			return err
+		}
+
+		if err = appendRateHistory(ctx, tx, rate); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// updateRate returns store.ErrRowNotFound if there is no rate to update.
+func updateRate(ctx context.Context, e execer, rate *model.Rate) error {
+	return rowUpdated(e.ExecContext(ctx,
+		"UPDATE rate SET first_currency = ?, second_currency = ?, value = ?, last_update_time = ?, source = ?, pinned_until = ? WHERE id = ?",
+		rate.FirstCurrency, rate.SecondCurrency, rate.Value, utc(rate.LastUpdateTime), rate.Source, nullUTC(rate.PinnedUntil), rate.ID,
+	))
+}
+
+// updateUnpinnedRate is updateRate that treats the rate pinned at the moment as missing,
+// the pin may have been set after the rate was read.
+func updateUnpinnedRate(ctx context.Context, e execer, rate *model.Rate, now time.Time) error {
+	return rowUpdated(e.ExecContext(ctx,
+		"UPDATE rate SET first_currency = ?, second_currency = ?, value = ?, last_update_time = ?, source = ?, pinned_until = ? WHERE id = ? AND (pinned_until IS NULL OR pinned_until <= ?)",
+		rate.FirstCurrency, rate.SecondCurrency, rate.Value, utc(rate.LastUpdateTime), rate.Source, nullUTC(rate.PinnedUntil), rate.ID, utc(now),
+	))
+}
+
+// rowUpdated returns store.ErrRowNotFound if the statement affected no rows.
+func rowUpdated(res sql.Result, err error) error {
+	if err != nil {
+		return storeError(err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return store.ErrRowNotFound
+	}
+
+	return nil
+}
+
+func (r *RateRepository) Delete(ctx context.Context, id int) error {
+	res, err := r.store.db.ExecContext(ctx, "DELETE FROM rate WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return store.ErrRowNotFound
+	}
+
+	return nil
+}
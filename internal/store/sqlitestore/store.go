@@ -0,0 +1,111 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// URLScheme is the scheme of the DATABASE_URL selecting the SQLite store, e.g. "sqlite://data/currencyapi.db".
+const URLScheme = "sqlite://"
+
+var _ store.Store = (*Store)(nil)
+
+type Store struct {
+	db                    *sql.DB
+	rateRepository        *RateRepository
+	rateHistoryRepository *RateHistoryRepository
+	rateAuditRepository   *RateAuditRepository
+}
+
+func New(db *sql.DB) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+// IsURL reports whether the database URL selects the SQLite store.
+func IsURL(databaseURL string) bool {
+	return strings.HasPrefix(databaseURL, URLScheme)
+}
+
+// Open opens the SQLite database the URL points to, creating it if it doesn't exist.
+// The schema is created by the migrations in migrations/sqlite.
+func Open(ctx context.Context, databaseURL string) (*sql.DB, error) {
+	dsn := strings.TrimPrefix(databaseURL, URLScheme)
+	if strings.Contains(dsn, "?") {
+		dsn += "&_time_format=sqlite"
+	} else {
+		dsn += "?_time_format=sqlite"
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer at a time, so the connection is shared instead of waiting for the locks
+	db.SetMaxOpenConns(1)
+
+	if err = db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func (s *Store) Rate() store.RateRepository {
+	if s.rateRepository != nil {
+		return s.rateRepository
+	}
+
+	s.rateRepository = &RateRepository{
+		store: s,
+	}
+
+	return s.rateRepository
+}
+
+func (s *Store) RateHistory() store.RateHistoryRepository {
+	if s.rateHistoryRepository != nil {
+		return s.rateHistoryRepository
+	}
+
+	s.rateHistoryRepository = &RateHistoryRepository{
+		store: s,
+	}
+
+	return s.rateHistoryRepository
+}
+
+func (s *Store) RateAudit() store.RateAuditRepository {
+	if s.rateAuditRepository != nil {
+		return s.rateAuditRepository
+	}
+
+	s.rateAuditRepository = &RateAuditRepository{
+		store: s,
+	}
+
+	return s.rateAuditRepository
+}
+
+// utc converts the time to UTC, the times are stored as text and compared as strings,
+// so they must all be in the same time zone.
+func utc(t time.Time) time.Time {
+	return t.UTC()
+}
+
+// nullUTC is utc for the nullable times.
+func nullUTC(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+
+	return utc(*t)
+}
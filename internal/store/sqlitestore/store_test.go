@@ -0,0 +1,43 @@
+package sqlitestore_test
+
+import (
+	"context"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/sqlitestore"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/storetest"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		db, teardown := sqlitestore.TestDB(t)
+		t.Cleanup(teardown)
+
+		return sqlitestore.New(db)
+	})
+}
+
+func TestRateRepository_UpdateWithAudit_Rollback(t *testing.T) {
+	db, teardown := sqlitestore.TestDB(t)
+	defer teardown()
+
+	st := sqlitestore.New(db)
+
+	r := model.TestRate(t)
+	assert.NoError(t, st.Rate().Create(context.Background(), r))
+
+	// the audit entry can't be recorded
+	_, err := db.Exec("DROP TABLE rate_audit")
+	assert.NoError(t, err)
+
+	rUpd := *r
+	rUpd.Value = decimal.RequireFromString("80")
+	assert.Error(t, st.Rate().UpdateWithAudit(context.Background(), &rUpd, &model.RateAuditEntry{RateID: r.ID}))
+
+	rFind, err := st.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assert.True(t, r.Value.Equal(rFind.Value))
+}
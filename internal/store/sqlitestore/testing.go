@@ -0,0 +1,35 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/migrate"
+	"github.com/tmrrwnxtsn/currency-conversion-api/migrations"
+	"path/filepath"
+	"testing"
+)
+
+// TestDB opens a new migrated SQLite database in a temporary directory removed after the test.
+func TestDB(t *testing.T) (*sql.DB, func()) {
+	t.Helper()
+
+	db, err := Open(context.Background(), URLScheme+filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := migrate.New(db, migrations.SQLite())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return db, func() {
+		if err = db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
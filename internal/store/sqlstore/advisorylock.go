@@ -0,0 +1,119 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/leader"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/migrate"
+	"time"
+)
+
+var (
+	errAdvisoryLockLost = errors.New("advisory lock is no longer held")
+
+	_ leader.Lock  = (*AdvisoryLock)(nil)
+	_ migrate.Lock = (*AdvisoryLock)(nil)
+)
+
+// releaseTimeout is the time given to release the lock taken by Lock.
+const releaseTimeout = 5 * time.Second
+
+// AdvisoryLock is a leader.Lock based on a Postgres session-level advisory lock. The lock is held
+// by a dedicated connection, so it's released by Postgres as soon as the instance holding it dies.
+type AdvisoryLock struct {
+	db   *sql.DB
+	key  int64
+	conn *sql.Conn
+}
+
+func NewAdvisoryLock(db *sql.DB, key int64) *AdvisoryLock {
+	return &AdvisoryLock{
+		db:  db,
+		key: key,
+	}
+}
+
+func (l *AdvisoryLock) TryAcquire(ctx context.Context) (bool, error) {
+	if l.conn != nil {
+		return true, nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	if err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&acquired); err != nil || !acquired {
+		_ = conn.Close()
+		return false, err
+	}
+
+	l.conn = conn
+
+	return true, nil
+}
+
+// Lock waits until the lock is acquired and returns the function releasing it.
+func (l *AdvisoryLock) Lock(ctx context.Context) (func(), error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", l.key); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	l.conn = conn
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+		defer cancel()
+
+		_ = l.Release(ctx)
+	}, nil
+}
+
+func (l *AdvisoryLock) Check(ctx context.Context) error {
+	if l.conn == nil {
+		return errAdvisoryLockLost
+	}
+
+	// a bigint key is split into classid and objid, objsubid is 1 for bigint keys
+	var held bool
+	if err := l.conn.QueryRowContext(ctx,
+		`SELECT EXISTS (
+			SELECT 1 FROM pg_locks
+			WHERE locktype = 'advisory' AND pid = pg_backend_pid() AND granted
+			AND ((classid::bigint << 32) | objid::bigint) = $1 AND objsubid = 1
+		)`,
+		l.key,
+	).Scan(&held); err != nil {
+		return err
+	}
+
+	if !held {
+		return errAdvisoryLockLost
+	}
+
+	return nil
+}
+
+func (l *AdvisoryLock) Release(ctx context.Context) error {
+	if l.conn == nil {
+		return nil
+	}
+
+	conn := l.conn
+	l.conn = nil
+
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	if closeErr := conn.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
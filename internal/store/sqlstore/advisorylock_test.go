@@ -0,0 +1,59 @@
+package sqlstore_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/sqlstore"
+	"testing"
+	"time"
+)
+
+func TestAdvisoryLock(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown()
+
+	first := sqlstore.NewAdvisoryLock(db, 42)
+	second := sqlstore.NewAdvisoryLock(db, 42)
+
+	acquired, err := first.TryAcquire(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+	assert.NoError(t, first.Check(context.Background()))
+
+	acquired, err = second.TryAcquire(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, acquired)
+	assert.Error(t, second.Check(context.Background()))
+
+	assert.NoError(t, first.Release(context.Background()))
+	assert.Error(t, first.Check(context.Background()))
+
+	acquired, err = second.TryAcquire(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+	assert.NoError(t, second.Release(context.Background()))
+}
+
+func TestAdvisoryLock_Lock(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown()
+
+	first := sqlstore.NewAdvisoryLock(db, 42)
+	second := sqlstore.NewAdvisoryLock(db, 42)
+
+	release, err := first.Lock(context.Background())
+	assert.NoError(t, err)
+
+	// the second lock waits for the first one to be released
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = second.Lock(ctx)
+	assert.Error(t, err)
+
+	release()
+
+	release, err = second.Lock(context.Background())
+	assert.NoError(t, err)
+	release()
+}
@@ -0,0 +1,30 @@
+package sqlstore
+
+import (
+	"errors"
+	"github.com/lib/pq"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+)
+
+// the codes of the errors of the constraint violations, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	uniqueViolation = "23505"
+	checkViolation  = "23514"
+)
+
+// storeError maps the constraint violations to the store errors.
+func storeError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case uniqueViolation:
+		return store.ErrDuplicate
+	case checkViolation:
+		return store.ErrInvalidRow
+	default:
+		return err
+	}
+}
@@ -0,0 +1,61 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+)
+
+var _ store.RateAuditRepository = (*RateAuditRepository)(nil)
+
+type RateAuditRepository struct {
+	store *Store
+}
+
+// queryRower is either the database or a transaction.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (r *RateAuditRepository) Create(ctx context.Context, entry *model.RateAuditEntry) error {
+	return createRateAudit(ctx, r.store.db, entry)
+}
+
+func createRateAudit(ctx context.Context, q queryRower, entry *model.RateAuditEntry) error {
+	return q.QueryRowContext(ctx,
+		`INSERT INTO rate_audit (rate_id, first_currency, second_currency, value, pinned_until, author, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+		entry.RateID, entry.FirstCurrency, entry.SecondCurrency, entry.Value, nullUTC(entry.PinnedUntil), entry.Author, entry.Reason, utc(entry.CreatedAt),
+	).Scan(&entry.ID)
+}
+
+func (r *RateAuditRepository) FindByRate(ctx context.Context, rateID int) ([]*model.RateAuditEntry, error) {
+	rows, err := r.store.db.QueryContext(ctx,
+		`SELECT id, rate_id, first_currency, second_currency, value, pinned_until, author, reason, created_at
+		FROM rate_audit WHERE rate_id = $1 ORDER BY created_at, id`,
+		rateID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*model.RateAuditEntry, 0)
+	for rows.Next() {
+		entry := &model.RateAuditEntry{}
+		if err = rows.Scan(
+			&entry.ID, &entry.RateID, &entry.FirstCurrency, &entry.SecondCurrency, &entry.Value,
+			&entry.PinnedUntil, &entry.Author, &entry.Reason, &entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -1,9 +1,12 @@
 package sqlstore
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"time"
 )
 
 var _ store.RateRepository = (*RateRepository)(nil)
@@ -12,23 +15,37 @@ type RateRepository struct {
 	store *Store
 }
 
-func (r *RateRepository) Create(rate *model.Rate) error {
+func (r *RateRepository) Create(ctx context.Context, rate *model.Rate) error {
 	if err := rate.Validate(); err != nil {
 		return err
 	}
 
-	return r.store.db.QueryRow(
-		"INSERT INTO rate (first_currency, second_currency, value, last_update_time) VALUES ($1, $2, $3, $4) RETURNING id",
-		rate.FirstCurrency, rate.SecondCurrency, rate.Value, rate.LastUpdateTime,
-	).Scan(&rate.ID)
+	tx, err := r.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = tx.QueryRowContext(ctx,
+		"INSERT INTO rate (first_currency, second_currency, value, last_update_time, source, pinned_until) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		rate.FirstCurrency, rate.SecondCurrency, rate.Value, utc(rate.LastUpdateTime), rate.Source, nullUTC(rate.PinnedUntil),
+	).Scan(&rate.ID); err != nil {
+		return storeError(err)
+	}
+
+	if err = appendRateHistory(ctx, tx, rate); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *RateRepository) Find(id int) (*model.Rate, error) {
+func (r *RateRepository) Find(ctx context.Context, id int) (*model.Rate, error) {
 	rate := &model.Rate{}
-	if err := r.store.db.QueryRow(
-		"SELECT id, first_currency, second_currency, value, last_update_time FROM rate WHERE id = $1",
+	if err := r.store.db.QueryRowContext(ctx,
+		"SELECT id, first_currency, second_currency, value, last_update_time, source, pinned_until FROM rate WHERE id = $1",
 		id,
-	).Scan(&rate.ID, &rate.FirstCurrency, &rate.SecondCurrency, &rate.Value, &rate.LastUpdateTime); err != nil {
+	).Scan(&rate.ID, &rate.FirstCurrency, &rate.SecondCurrency, &rate.Value, &rate.LastUpdateTime, &rate.Source, &rate.PinnedUntil); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, store.ErrRowNotFound
 		}
@@ -39,12 +56,12 @@ func (r *RateRepository) Find(id int) (*model.Rate, error) {
 	return rate, nil
 }
 
-func (r *RateRepository) FindByCurrencies(firstCurrency, secondCurrency string) (*model.Rate, error) {
+func (r *RateRepository) FindByCurrencies(ctx context.Context, firstCurrency, secondCurrency string) (*model.Rate, error) {
 	rate := &model.Rate{}
-	if err := r.store.db.QueryRow(
-		"SELECT id, first_currency, second_currency, value, last_update_time FROM rate WHERE first_currency = $1 AND second_currency = $2",
+	if err := r.store.db.QueryRowContext(ctx,
+		"SELECT id, first_currency, second_currency, value, last_update_time, source, pinned_until FROM rate WHERE first_currency = $1 AND second_currency = $2",
 		firstCurrency, secondCurrency,
-	).Scan(&rate.ID, &rate.FirstCurrency, &rate.SecondCurrency, &rate.Value, &rate.LastUpdateTime); err != nil {
+	).Scan(&rate.ID, &rate.FirstCurrency, &rate.SecondCurrency, &rate.Value, &rate.LastUpdateTime, &rate.Source, &rate.PinnedUntil); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, store.ErrRowNotFound
 		}
@@ -55,10 +72,10 @@ func (r *RateRepository) FindByCurrencies(firstCurrency, secondCurrency string)
 	return rate, nil
 }
 
-func (r *RateRepository) FindAll() ([]*model.Rate, error) {
+func (r *RateRepository) FindAll(ctx context.Context) ([]*model.Rate, error) {
 	var rates []*model.Rate
 
-	rows, err := r.store.db.Query("SELECT id, first_currency, second_currency, value, last_update_time FROM rate")
+	rows, err := r.store.db.QueryContext(ctx, "SELECT id, first_currency, second_currency, value, last_update_time, source, pinned_until FROM rate")
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +83,7 @@ func (r *RateRepository) FindAll() ([]*model.Rate, error) {
 
 	for rows.Next() {
 		rate := &model.Rate{}
-		if err = rows.Scan(&rate.ID, &rate.FirstCurrency, &rate.SecondCurrency, &rate.Value, &rate.LastUpdateTime); err != nil {
+		if err = rows.Scan(&rate.ID, &rate.FirstCurrency, &rate.SecondCurrency, &rate.Value, &rate.LastUpdateTime, &rate.Source, &rate.PinnedUntil); err != nil {
 			return nil, err
 		}
 		rates = append(rates, rate)
@@ -79,18 +96,202 @@ func (r *RateRepository) FindAll() ([]*model.Rate, error) {
 	return rates, nil
 }
 
-func (r *RateRepository) Update(rate *model.Rate) error {
+func (r *RateRepository) List(ctx context.Context, filter *model.RateFilter) ([]*model.Rate, int, error) {
+	where := "WHERE ($1 = '' OR first_currency = $1 OR second_currency = $1) AND ($2 = '' OR first_currency = $2) AND ($3 = '' OR second_currency = $3)"
+	args := []interface{}{filter.Currency, filter.FirstCurrency, filter.SecondCurrency}
+
+	var total int
+	if err := r.store.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM rate "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// LIMIT NULL means no limit
+	var limit interface{}
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+
+	rows, err := r.store.db.QueryContext(ctx,
+		fmt.Sprintf("SELECT id, first_currency, second_currency, value, last_update_time, source, pinned_until FROM rate %s ORDER BY id LIMIT $4 OFFSET $5", where),
+		append(args, limit, filter.Offset)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	rates := make([]*model.Rate, 0)
+	for rows.Next() {
+		rate := &model.Rate{}
+		if err = rows.Scan(&rate.ID, &rate.FirstCurrency, &rate.SecondCurrency, &rate.Value, &rate.LastUpdateTime, &rate.Source, &rate.PinnedUntil); err != nil {
+			return nil, 0, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return rates, total, nil
+}
+
+func (r *RateRepository) Update(ctx context.Context, rate *model.Rate) error {
+	if err := rate.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := r.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = updateRate(ctx, tx, rate); err != nil {
+		return err
+	}
+
+	if err = appendRateHistory(ctx, tx, rate); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RateRepository) UpdateWithAudit(ctx context.Context, rate *model.Rate, entry *model.RateAuditEntry) error {
 	if err := rate.Validate(); err != nil {
 		return err
 	}
 
-	findRate, err := r.Find(rate.ID)
+	tx, err := r.store.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	if err = updateRate(ctx, tx, rate); err != nil {
+		return err
+	}
+
+	if err = appendRateHistory(ctx, tx, rate); err != nil {
+		return err
+	}
+
+	if err = createRateAudit(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RateRepository) Upsert(ctx context.Context, rate *model.Rate) error {
+	if err := rate.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := r.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = tx.QueryRowContext(ctx,
+		`INSERT INTO rate (first_currency, second_currency, value, last_update_time, source, pinned_until) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (first_currency, second_currency) DO UPDATE
+		SET value = EXCLUDED.value, last_update_time = EXCLUDED.last_update_time, source = EXCLUDED.source, pinned_until = EXCLUDED.pinned_until
+		RETURNING id`,
+		rate.FirstCurrency, rate.SecondCurrency, rate.Value, utc(rate.LastUpdateTime), rate.Source, nullUTC(rate.PinnedUntil),
+	).Scan(&rate.ID); err != nil {
+		return storeError(err)
+	}
+
+	if err = appendRateHistory(ctx, tx, rate); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RateRepository) UpdateMany(ctx context.Context, rates []*model.Rate) error {
+	for _, rate := range rates {
+		if err := rate.Validate(); err != nil {
+			return err
+		}
+	}
+
+	tx, err := r.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, rate := range rates {
+		if err = updateUnpinnedRate(ctx, tx, rate, now); err != nil {
+			if err == store.ErrRowNotFound {
+				continue
+			}
+
+			return err
+		}
+
+		if err = appendRateHistory(ctx, tx, rate); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// updateRate returns store.ErrRowNotFound if there is no rate to update.
+func updateRate(ctx context.Context, e execer, rate *model.Rate) error {
+	return rowUpdated(e.ExecContext(ctx,
+		"UPDATE rate SET first_currency = $2, second_currency = $3, value = $4, last_update_time = $5, source = $6, pinned_until = $7 WHERE id = $1",
+		rate.ID, rate.FirstCurrency, rate.SecondCurrency, rate.Value, utc(rate.LastUpdateTime), rate.Source, nullUTC(rate.PinnedUntil),
+	))
+}
+
+// updateUnpinnedRate is updateRate that treats the rate pinned at the moment as missing,
+// the pin may have been set after the rate was read.
+func updateUnpinnedRate(ctx context.Context, e execer, rate *model.Rate, now time.Time) error {
+	return rowUpdated(e.ExecContext(ctx,
+		"UPDATE rate SET first_currency = $2, second_currency = $3, value = $4, last_update_time = $5, source = $6, pinned_until = $7 WHERE id = $1 AND (pinned_until IS NULL OR pinned_until <= $8)",
+		rate.ID, rate.FirstCurrency, rate.SecondCurrency, rate.Value, utc(rate.LastUpdateTime), rate.Source, nullUTC(rate.PinnedUntil), utc(now),
+	))
+}
+
+// rowUpdated returns store.ErrRowNotFound if the statement affected no rows.
+func rowUpdated(res sql.Result, err error) error {
+	if err != nil {
+		return storeError(err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return store.ErrRowNotFound
+	}
+
+	return nil
+}
+
+func (r *RateRepository) Delete(ctx context.Context, id int) error {
+	res, err := r.store.db.ExecContext(ctx, "DELETE FROM rate WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return store.ErrRowNotFound
+	}
 
-	return r.store.db.QueryRow(
-		"UPDATE rate SET first_currency = $2, second_currency = $3, value = $4, last_update_time = $5 WHERE id = $1 RETURNING id",
-		findRate.ID, rate.FirstCurrency, rate.SecondCurrency, rate.Value, rate.LastUpdateTime,
-	).Scan(&rate.ID)
+	return nil
 }
@@ -3,6 +3,7 @@ package sqlstore
 import (
 	"database/sql"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"time"
 
 	_ "github.com/lib/pq"
 )
@@ -10,8 +11,10 @@ import (
 var _ store.Store = (*Store)(nil)
 
 type Store struct {
-	db             *sql.DB
-	rateRepository *RateRepository
+	db                    *sql.DB
+	rateRepository        *RateRepository
+	rateHistoryRepository *RateHistoryRepository
+	rateAuditRepository   *RateAuditRepository
 }
 
 func New(db *sql.DB) *Store {
@@ -31,3 +34,42 @@ func (s *Store) Rate() store.RateRepository {
 
 	return s.rateRepository
 }
+
+func (s *Store) RateHistory() store.RateHistoryRepository {
+	if s.rateHistoryRepository != nil {
+		return s.rateHistoryRepository
+	}
+
+	s.rateHistoryRepository = &RateHistoryRepository{
+		store: s,
+	}
+
+	return s.rateHistoryRepository
+}
+
+func (s *Store) RateAudit() store.RateAuditRepository {
+	if s.rateAuditRepository != nil {
+		return s.rateAuditRepository
+	}
+
+	s.rateAuditRepository = &RateAuditRepository{
+		store: s,
+	}
+
+	return s.rateAuditRepository
+}
+
+// utc converts the time to UTC before it's bound, the time columns have no time zone,
+// so Postgres would drop the offset and store the wall-clock time of any other zone.
+func utc(t time.Time) time.Time {
+	return t.UTC()
+}
+
+// nullUTC is utc for the nullable times.
+func nullUTC(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+
+	return utc(*t)
+}
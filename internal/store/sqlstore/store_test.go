@@ -1,6 +1,9 @@
 package sqlstore_test
 
 import (
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/sqlstore"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/storetest"
 	"os"
 	"testing"
 )
@@ -15,3 +18,12 @@ func TestMain(m *testing.M) {
 
 	os.Exit(m.Run())
 }
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		db, teardown := sqlstore.TestDB(t, databaseURL)
+		t.Cleanup(func() { teardown("rate", "rate_history", "rate_audit") })
+
+		return sqlstore.New(db)
+	})
+}
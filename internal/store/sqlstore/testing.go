@@ -1,8 +1,11 @@
 package sqlstore
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/migrate"
+	"github.com/tmrrwnxtsn/currency-conversion-api/migrations"
 	"strings"
 	"testing"
 )
@@ -19,6 +22,15 @@ func TestDB(t *testing.T, databaseURL string) (*sql.DB, func(...string)) {
 		t.Fatal(err)
 	}
 
+	migrator, err := migrate.New(db, migrations.Postgres())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
 	return db, func(tables ...string) {
 		if len(tables) > 0 {
 			query := fmt.Sprintf("TRUNCATE %s CASCADE", strings.Join(tables, ", "))
@@ -4,4 +4,10 @@ package store
 type Store interface {
 	// Rate ...
 	Rate() RateRepository
+
+	// RateHistory ...
+	RateHistory() RateHistoryRepository
+
+	// RateAudit ...
+	RateAudit() RateAuditRepository
 }
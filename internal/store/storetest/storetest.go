@@ -0,0 +1,596 @@
+// Package storetest is the contract test suite every store.Store implementation has to pass.
+package storetest
+
+import (
+	"context"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"testing"
+	"time"
+)
+
+// timePrecision is the precision the stores keep the times with at least.
+const timePrecision = time.Millisecond
+
+// Run runs the contract tests, each of them on a new empty store created by newStore.
+func Run(t *testing.T, newStore func(t *testing.T) store.Store) {
+	t.Helper()
+
+	testCases := []struct {
+		name string
+		test func(t *testing.T, st store.Store)
+	}{
+		{name: "Rate/Create", test: testRateCreate},
+		{name: "Rate/CreateDuplicate", test: testRateCreateDuplicate},
+		{name: "Rate/Find", test: testRateFind},
+		{name: "Rate/FindByCurrencies", test: testRateFindByCurrencies},
+		{name: "Rate/FindAll", test: testRateFindAll},
+		{name: "Rate/Update", test: testRateUpdate},
+		{name: "Rate/UpdateWithAudit", test: testRateUpdateWithAudit},
+		{name: "Rate/Upsert", test: testRateUpsert},
+		{name: "Rate/UpdateMany", test: testRateUpdateMany},
+		{name: "Rate/UpdateManyPinned", test: testRateUpdateManyPinned},
+		{name: "Rate/PinTimeZones", test: testRatePinTimeZones},
+		{name: "Rate/Delete", test: testRateDelete},
+		{name: "Rate/List", test: testRateList},
+		{name: "RateHistory/FindRange", test: testRateHistoryFindRange},
+		{name: "RateHistory/FindAsOf", test: testRateHistoryFindAsOf},
+		{name: "RateHistory/TimeZones", test: testRateHistoryTimeZones},
+		{name: "RateAudit/FindByRate", test: testRateAuditFindByRate},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.test(t, newStore(t))
+		})
+	}
+}
+
+func testRate(t *testing.T, firstCurrency, secondCurrency string) *model.Rate {
+	t.Helper()
+
+	r := model.TestRate(t)
+	r.FirstCurrency, r.SecondCurrency = firstCurrency, secondCurrency
+
+	return r
+}
+
+func assertRate(t *testing.T, expected, actual *model.Rate) {
+	t.Helper()
+
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.FirstCurrency, actual.FirstCurrency)
+	assert.Equal(t, expected.SecondCurrency, actual.SecondCurrency)
+	assert.True(t, expected.Value.Equal(actual.Value), "expected value %s, actual %s", expected.Value, actual.Value)
+	assert.WithinDuration(t, expected.LastUpdateTime, actual.LastUpdateTime, timePrecision)
+	assert.Equal(t, expected.Source, actual.Source)
+
+	if expected.PinnedUntil == nil {
+		assert.Nil(t, actual.PinnedUntil)
+	} else if assert.NotNil(t, actual.PinnedUntil) {
+		assert.WithinDuration(t, *expected.PinnedUntil, *actual.PinnedUntil, timePrecision)
+	}
+}
+
+func testRateCreate(t *testing.T, st store.Store) {
+	r1 := testRate(t, "USD", "RUB")
+	assert.NoError(t, st.Rate().Create(context.Background(), r1))
+	assert.NotZero(t, r1.ID)
+
+	r2 := testRate(t, "EUR", "RUB")
+	assert.NoError(t, st.Rate().Create(context.Background(), r2))
+	assert.NotEqual(t, r1.ID, r2.ID)
+
+	rInvalid := testRate(t, "USD", "JPY")
+	rInvalid.Value = decimal.NewFromInt(-1)
+	assert.Error(t, st.Rate().Create(context.Background(), rInvalid))
+
+	_, err := st.Rate().FindByCurrencies(context.Background(), "USD", "JPY")
+	assert.ErrorIs(t, err, store.ErrRowNotFound)
+}
+
+func testRateCreateDuplicate(t *testing.T, st store.Store) {
+	assert.NoError(t, st.Rate().Create(context.Background(), testRate(t, "USD", "RUB")))
+	assert.ErrorIs(t, st.Rate().Create(context.Background(), testRate(t, "USD", "RUB")), store.ErrDuplicate)
+
+	r := testRate(t, "RUB", "USD")
+	assert.NoError(t, st.Rate().Create(context.Background(), r))
+
+	rUpd := *r
+	rUpd.FirstCurrency, rUpd.SecondCurrency = "USD", "RUB"
+	assert.ErrorIs(t, st.Rate().Update(context.Background(), &rUpd), store.ErrDuplicate)
+
+	rates, err := st.Rate().FindAll(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(rates))
+}
+
+func testRateFind(t *testing.T, st store.Store) {
+	r := testRate(t, "USD", "RUB")
+	r.Value = decimal.RequireFromString("75.123456789012345678")
+	assert.NoError(t, st.Rate().Create(context.Background(), r))
+
+	rFind, err := st.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assertRate(t, r, rFind)
+
+	_, err = st.Rate().Find(context.Background(), r.ID+1)
+	assert.ErrorIs(t, err, store.ErrRowNotFound)
+}
+
+func testRateFindByCurrencies(t *testing.T, st store.Store) {
+	r := testRate(t, "USD", "RUB")
+
+	_, err := st.Rate().FindByCurrencies(context.Background(), "USD", "RUB")
+	assert.ErrorIs(t, err, store.ErrRowNotFound)
+
+	assert.NoError(t, st.Rate().Create(context.Background(), r))
+
+	rFind, err := st.Rate().FindByCurrencies(context.Background(), "USD", "RUB")
+	assert.NoError(t, err)
+	assertRate(t, r, rFind)
+
+	_, err = st.Rate().FindByCurrencies(context.Background(), "RUB", "USD")
+	assert.ErrorIs(t, err, store.ErrRowNotFound)
+}
+
+func testRateFindAll(t *testing.T, st store.Store) {
+	rates, err := st.Rate().FindAll(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, rates)
+
+	for _, pair := range [][2]string{{"USD", "RUB"}, {"EUR", "USD"}, {"BRL", "CAD"}} {
+		assert.NoError(t, st.Rate().Create(context.Background(), testRate(t, pair[0], pair[1])))
+	}
+
+	rates, err = st.Rate().FindAll(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(rates))
+}
+
+func testRateUpdate(t *testing.T, st store.Store) {
+	r := testRate(t, "USD", "RUB")
+	assert.NoError(t, st.Rate().Create(context.Background(), r))
+
+	pinnedUntil := time.Now().Add(time.Hour)
+	rUpd := &model.Rate{
+		ID:             r.ID,
+		FirstCurrency:  "EUR",
+		SecondCurrency: "USD",
+		Value:          decimal.RequireFromString("1.1"),
+		LastUpdateTime: time.Now(),
+		Source:         "manual",
+		PinnedUntil:    &pinnedUntil,
+	}
+	assert.NoError(t, st.Rate().Update(context.Background(), rUpd))
+
+	rFind, err := st.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assertRate(t, rUpd, rFind)
+
+	rInvalid := *rUpd
+	rInvalid.Value = decimal.NewFromInt(-1)
+	assert.Error(t, st.Rate().Update(context.Background(), &rInvalid))
+
+	rMissing := *rUpd
+	rMissing.ID = r.ID + 1
+	assert.ErrorIs(t, st.Rate().Update(context.Background(), &rMissing), store.ErrRowNotFound)
+}
+
+func testRateUpdateWithAudit(t *testing.T, st store.Store) {
+	r := testRate(t, "USD", "RUB")
+	assert.NoError(t, st.Rate().Create(context.Background(), r))
+
+	pinnedUntil := time.Now().Add(time.Hour)
+	rUpd := *r
+	rUpd.Value = decimal.RequireFromString("80")
+	rUpd.LastUpdateTime = time.Now()
+	rUpd.Source = "manual"
+	rUpd.PinnedUntil = &pinnedUntil
+
+	entry := &model.RateAuditEntry{
+		RateID:         r.ID,
+		FirstCurrency:  rUpd.FirstCurrency,
+		SecondCurrency: rUpd.SecondCurrency,
+		Value:          rUpd.Value,
+		PinnedUntil:    rUpd.PinnedUntil,
+		Author:         "j.doe",
+		Reason:         "test",
+		CreatedAt:      rUpd.LastUpdateTime,
+	}
+	assert.NoError(t, st.Rate().UpdateWithAudit(context.Background(), &rUpd, entry))
+	assert.NotZero(t, entry.ID)
+
+	rFind, err := st.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assertRate(t, &rUpd, rFind)
+
+	found, err := st.RateAudit().FindByRate(context.Background(), r.ID)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(found)) {
+		assert.Equal(t, entry.ID, found[0].ID)
+	}
+
+	// the entry of a change that wasn't made isn't recorded
+	rMissing := rUpd
+	rMissing.ID = r.ID + 1
+	entryMissing := *entry
+	entryMissing.ID, entryMissing.RateID = 0, rMissing.ID
+	assert.ErrorIs(t, st.Rate().UpdateWithAudit(context.Background(), &rMissing, &entryMissing), store.ErrRowNotFound)
+
+	found, err = st.RateAudit().FindByRate(context.Background(), rMissing.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func testRateUpsert(t *testing.T, st store.Store) {
+	r := testRate(t, "USD", "RUB")
+	r.LastUpdateTime = time.Now().Add(-time.Hour)
+	assert.NoError(t, st.Rate().Upsert(context.Background(), r))
+	assert.NotZero(t, r.ID)
+
+	rFind, err := st.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assertRate(t, r, rFind)
+
+	rUpd := testRate(t, "USD", "RUB")
+	rUpd.Value = decimal.RequireFromString("80.1")
+	assert.NoError(t, st.Rate().Upsert(context.Background(), rUpd))
+	assert.Equal(t, r.ID, rUpd.ID)
+
+	rFind, err = st.Rate().FindByCurrencies(context.Background(), "USD", "RUB")
+	assert.NoError(t, err)
+	assertRate(t, rUpd, rFind)
+
+	rates, err := st.Rate().FindAll(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(rates))
+
+	points, err := st.RateHistory().FindRange(context.Background(), "USD", "RUB", r.LastUpdateTime, rUpd.LastUpdateTime, model.HistoryIntervalRaw)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(points))
+
+	rInvalid := testRate(t, "USD", "RUB")
+	rInvalid.Value = decimal.NewFromInt(-1)
+	assert.Error(t, st.Rate().Upsert(context.Background(), rInvalid))
+}
+
+func testRateUpdateMany(t *testing.T, st store.Store) {
+	var rates []*model.Rate
+	for _, pair := range [][2]string{{"USD", "RUB"}, {"USD", "EUR"}, {"USD", "JPY"}} {
+		r := testRate(t, pair[0], pair[1])
+		assert.NoError(t, st.Rate().Create(context.Background(), r))
+		rates = append(rates, r)
+	}
+
+	assert.NoError(t, st.Rate().Delete(context.Background(), rates[2].ID))
+
+	var rUpds []*model.Rate
+	for _, r := range rates {
+		rUpd := *r
+		rUpd.Value = decimal.RequireFromString("2.5")
+		rUpds = append(rUpds, &rUpd)
+	}
+	assert.NoError(t, st.Rate().UpdateMany(context.Background(), rUpds))
+
+	for _, r := range rUpds[:2] {
+		rFind, err := st.Rate().Find(context.Background(), r.ID)
+		assert.NoError(t, err)
+		assertRate(t, r, rFind)
+	}
+
+	_, err := st.Rate().Find(context.Background(), rates[2].ID)
+	assert.ErrorIs(t, err, store.ErrRowNotFound)
+
+	// nothing is updated if any of the rates is invalid
+	rValid, rInvalid := *rUpds[0], *rUpds[1]
+	rValid.Value = decimal.RequireFromString("3")
+	rInvalid.Value = decimal.NewFromInt(-1)
+	assert.Error(t, st.Rate().UpdateMany(context.Background(), []*model.Rate{&rValid, &rInvalid}))
+
+	rFind, err := st.Rate().Find(context.Background(), rates[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.5", rFind.Value.String())
+}
+
+func testRateUpdateManyPinned(t *testing.T, st store.Store) {
+	r := testRate(t, "USD", "RUB")
+	assert.NoError(t, st.Rate().Create(context.Background(), r))
+
+	rates, err := st.Rate().FindAll(context.Background())
+	assert.NoError(t, err)
+
+	// the rate is pinned after it was read for the update
+	pinnedUntil := time.Now().Add(time.Hour)
+	rPinned := *r
+	rPinned.Value = decimal.RequireFromString("100")
+	rPinned.PinnedUntil = &pinnedUntil
+	assert.NoError(t, st.Rate().Update(context.Background(), &rPinned))
+
+	var rUpds []*model.Rate
+	for _, r := range rates {
+		rUpd := *r
+		rUpd.Value = decimal.RequireFromString("2.5")
+		rUpds = append(rUpds, &rUpd)
+	}
+	assert.NoError(t, st.Rate().UpdateMany(context.Background(), rUpds))
+
+	rFind, err := st.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assertRate(t, &rPinned, rFind)
+
+	// the expired pin doesn't prevent the update
+	expired := time.Now().Add(-time.Minute)
+	rPinned.PinnedUntil = &expired
+	assert.NoError(t, st.Rate().Update(context.Background(), &rPinned))
+	assert.NoError(t, st.Rate().UpdateMany(context.Background(), rUpds))
+
+	rFind, err = st.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assertRate(t, rUpds[0], rFind)
+}
+
+// testRatePinTimeZones checks the pins and their audit entries keep the instants whatever zones they're in.
+func testRatePinTimeZones(t *testing.T, st store.Store) {
+	moscow := time.FixedZone("UTC+3", 3*60*60)
+
+	r := testRate(t, "USD", "RUB")
+	assert.NoError(t, st.Rate().Create(context.Background(), r))
+
+	// if the offset were dropped, the rate would stay pinned 3 hours longer
+	pinnedUntil := time.Now().Add(-time.Hour).In(moscow)
+	r.PinnedUntil = &pinnedUntil
+	assert.NoError(t, st.Rate().Update(context.Background(), r))
+
+	rFind, err := st.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assertRate(t, r, rFind)
+	assert.False(t, rFind.IsPinned(time.Now()))
+
+	rUpd := *r
+	rUpd.Value = decimal.RequireFromString("2.5")
+	rUpd.PinnedUntil = nil
+	assert.NoError(t, st.Rate().UpdateMany(context.Background(), []*model.Rate{&rUpd}))
+
+	rFind, err = st.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assertRate(t, &rUpd, rFind)
+
+	entry := &model.RateAuditEntry{
+		RateID:         r.ID,
+		FirstCurrency:  r.FirstCurrency,
+		SecondCurrency: r.SecondCurrency,
+		Value:          r.Value,
+		PinnedUntil:    &pinnedUntil,
+		Author:         "j.doe",
+		Reason:         "test",
+		CreatedAt:      time.Now().In(moscow),
+	}
+	assert.NoError(t, st.RateAudit().Create(context.Background(), entry))
+
+	entries, err := st.RateAudit().FindByRate(context.Background(), r.ID)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(entries)) && assert.NotNil(t, entries[0].PinnedUntil) {
+		assert.WithinDuration(t, pinnedUntil, *entries[0].PinnedUntil, timePrecision)
+		assert.WithinDuration(t, entry.CreatedAt, entries[0].CreatedAt, timePrecision)
+	}
+}
+
+func testRateDelete(t *testing.T, st store.Store) {
+	r := testRate(t, "USD", "RUB")
+	assert.NoError(t, st.Rate().Create(context.Background(), r))
+
+	assert.NoError(t, st.Rate().Delete(context.Background(), r.ID))
+
+	_, err := st.Rate().Find(context.Background(), r.ID)
+	assert.ErrorIs(t, err, store.ErrRowNotFound)
+
+	assert.ErrorIs(t, st.Rate().Delete(context.Background(), r.ID), store.ErrRowNotFound)
+}
+
+func testRateList(t *testing.T, st store.Store) {
+	var ids []int
+	for _, pair := range [][2]string{{"USD", "RUB"}, {"EUR", "USD"}, {"BRL", "CAD"}, {"USD", "JPY"}} {
+		r := testRate(t, pair[0], pair[1])
+		assert.NoError(t, st.Rate().Create(context.Background(), r))
+		ids = append(ids, r.ID)
+	}
+
+	testCases := []struct {
+		name          string
+		filter        *model.RateFilter
+		expectedIDs   []int
+		expectedTotal int
+	}{
+		{
+			name:          "all",
+			filter:        &model.RateFilter{},
+			expectedIDs:   ids,
+			expectedTotal: 4,
+		},
+		{
+			name:          "by currency",
+			filter:        &model.RateFilter{Currency: "USD"},
+			expectedIDs:   []int{ids[0], ids[1], ids[3]},
+			expectedTotal: 3,
+		},
+		{
+			name:          "by first currency",
+			filter:        &model.RateFilter{FirstCurrency: "USD"},
+			expectedIDs:   []int{ids[0], ids[3]},
+			expectedTotal: 2,
+		},
+		{
+			name:          "by both currencies",
+			filter:        &model.RateFilter{FirstCurrency: "USD", SecondCurrency: "JPY"},
+			expectedIDs:   []int{ids[3]},
+			expectedTotal: 1,
+		},
+		{
+			name:          "page",
+			filter:        &model.RateFilter{Currency: "USD", Limit: 2, Offset: 1},
+			expectedIDs:   []int{ids[1], ids[3]},
+			expectedTotal: 3,
+		},
+		{
+			name:          "offset out of range",
+			filter:        &model.RateFilter{Offset: 10},
+			expectedIDs:   []int{},
+			expectedTotal: 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rates, total, err := st.Rate().List(context.Background(), tc.filter)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedTotal, total)
+
+			actualIDs := make([]int, 0, len(rates))
+			for _, r := range rates {
+				actualIDs = append(actualIDs, r.ID)
+			}
+			assert.Equal(t, tc.expectedIDs, actualIDs)
+		})
+	}
+}
+
+func testRateHistoryFindRange(t *testing.T, st store.Store) {
+	day := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	var rates []*model.Rate
+	for i, offset := range []time.Duration{10 * time.Hour, 12 * time.Hour, 33 * time.Hour} {
+		r := testRate(t, "USD", "RUB")
+		r.Value = decimal.NewFromInt(int64(70 + i))
+		r.LastUpdateTime = day.Add(offset)
+		assert.NoError(t, st.RateHistory().Append(context.Background(), r))
+		rates = append(rates, r)
+	}
+
+	// another pair's points are never returned
+	assert.NoError(t, st.RateHistory().Append(context.Background(), testRate(t, "RUB", "USD")))
+
+	points, err := st.RateHistory().FindRange(context.Background(), "USD", "RUB", day, day.Add(48*time.Hour), model.HistoryIntervalRaw)
+	assert.NoError(t, err)
+	if assert.Equal(t, 3, len(points)) {
+		for i, r := range rates {
+			assert.True(t, r.Value.Equal(points[i].Value))
+			assert.WithinDuration(t, r.LastUpdateTime, points[i].Time, timePrecision)
+			assert.Equal(t, r.Source, points[i].Source)
+		}
+	}
+
+	// both bounds are inclusive
+	points, err = st.RateHistory().FindRange(context.Background(), "USD", "RUB", rates[1].LastUpdateTime, rates[2].LastUpdateTime, model.HistoryIntervalRaw)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(points))
+
+	// the last point of every bucket is kept at the start of the bucket
+	points, err = st.RateHistory().FindRange(context.Background(), "USD", "RUB", day, day.Add(48*time.Hour), model.HistoryIntervalDay)
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(points)) {
+		assert.True(t, rates[1].Value.Equal(points[0].Value))
+		assert.True(t, day.Equal(points[0].Time))
+		assert.True(t, rates[2].Value.Equal(points[1].Value))
+		assert.True(t, day.Add(24*time.Hour).Equal(points[1].Time))
+	}
+}
+
+func testRateHistoryFindAsOf(t *testing.T, st store.Store) {
+	r := testRate(t, "USD", "RUB")
+	r.LastUpdateTime = time.Now().Add(-2 * time.Hour)
+
+	_, err := st.RateHistory().FindAsOf(context.Background(), "USD", "RUB", time.Now())
+	assert.ErrorIs(t, err, store.ErrRowNotFound)
+
+	assert.NoError(t, st.Rate().Create(context.Background(), r))
+
+	rUpd := *r
+	rUpd.Value = decimal.RequireFromString("80.1")
+	rUpd.LastUpdateTime = time.Now().Add(-time.Hour)
+	assert.NoError(t, st.Rate().Update(context.Background(), &rUpd))
+
+	_, err = st.RateHistory().FindAsOf(context.Background(), "USD", "RUB", time.Now().Add(-3*time.Hour))
+	assert.ErrorIs(t, err, store.ErrRowNotFound)
+
+	point, err := st.RateHistory().FindAsOf(context.Background(), "USD", "RUB", time.Now().Add(-90*time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, r.Value.Equal(point.Value))
+
+	point, err = st.RateHistory().FindAsOf(context.Background(), "USD", "RUB", time.Now())
+	assert.NoError(t, err)
+	assert.True(t, rUpd.Value.Equal(point.Value))
+	assert.WithinDuration(t, rUpd.LastUpdateTime, point.Time, timePrecision)
+}
+
+// testRateHistoryTimeZones checks the times are compared and returned as instants whatever zones they're in.
+func testRateHistoryTimeZones(t *testing.T, st store.Store) {
+	moscow := time.FixedZone("UTC+3", 3*60*60)
+	newYork := time.FixedZone("UTC-5", -5*60*60)
+
+	r := testRate(t, "USD", "RUB")
+	r.LastUpdateTime = time.Now().Add(-time.Hour).In(moscow)
+	assert.NoError(t, st.Rate().Create(context.Background(), r))
+
+	rFind, err := st.Rate().Find(context.Background(), r.ID)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, r.LastUpdateTime, rFind.LastUpdateTime, timePrecision)
+
+	// if the offset were dropped, the rate would be recorded 3 hours after both of these moments
+	_, err = st.RateHistory().FindAsOf(context.Background(), "USD", "RUB", r.LastUpdateTime.Add(-time.Minute).UTC())
+	assert.ErrorIs(t, err, store.ErrRowNotFound)
+
+	point, err := st.RateHistory().FindAsOf(context.Background(), "USD", "RUB", r.LastUpdateTime.Add(time.Minute).UTC())
+	assert.NoError(t, err)
+	assert.WithinDuration(t, r.LastUpdateTime, point.Time, timePrecision)
+
+	points, err := st.RateHistory().FindRange(context.Background(), "USD", "RUB",
+		r.LastUpdateTime.Add(-time.Minute).In(newYork), r.LastUpdateTime.Add(time.Minute).In(newYork), model.HistoryIntervalRaw,
+	)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(points)) {
+		assert.WithinDuration(t, r.LastUpdateTime, points[0].Time, timePrecision)
+	}
+}
+
+func testRateAuditFindByRate(t *testing.T, st store.Store) {
+	pinnedUntil := time.Now().Add(time.Hour)
+
+	var entries []*model.RateAuditEntry
+	for i, rateID := range []int{1, 2, 1} {
+		entry := &model.RateAuditEntry{
+			RateID:         rateID,
+			FirstCurrency:  "USD",
+			SecondCurrency: "RUB",
+			Value:          decimal.NewFromInt(int64(70 + i)),
+			PinnedUntil:    &pinnedUntil,
+			Author:         "j.doe",
+			Reason:         "test",
+			CreatedAt:      time.Now().Add(time.Duration(i-3) * time.Minute),
+		}
+		assert.NoError(t, st.RateAudit().Create(context.Background(), entry))
+		assert.NotZero(t, entry.ID)
+		entries = append(entries, entry)
+	}
+
+	found, err := st.RateAudit().FindByRate(context.Background(), 1)
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(found)) {
+		for i, entry := range []*model.RateAuditEntry{entries[0], entries[2]} {
+			assert.Equal(t, entry.ID, found[i].ID)
+			assert.True(t, entry.Value.Equal(found[i].Value))
+			assert.Equal(t, entry.Author, found[i].Author)
+			assert.Equal(t, entry.Reason, found[i].Reason)
+			assert.WithinDuration(t, entry.CreatedAt, found[i].CreatedAt, timePrecision)
+			if assert.NotNil(t, found[i].PinnedUntil) {
+				assert.WithinDuration(t, pinnedUntil, *found[i].PinnedUntil, timePrecision)
+			}
+		}
+	}
+
+	found, err = st.RateAudit().FindByRate(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
@@ -0,0 +1,40 @@
+package teststore
+
+import (
+	"context"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"sync"
+)
+
+var _ store.RateAuditRepository = (*RateAuditRepository)(nil)
+
+type RateAuditRepository struct {
+	mu      sync.RWMutex
+	store   *Store
+	entries []*model.RateAuditEntry
+}
+
+func (r *RateAuditRepository) Create(ctx context.Context, entry *model.RateAuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.ID = len(r.entries) + 1
+	r.entries = append(r.entries, entry)
+
+	return nil
+}
+
+func (r *RateAuditRepository) FindByRate(ctx context.Context, rateID int) ([]*model.RateAuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]*model.RateAuditEntry, 0)
+	for _, entry := range r.entries {
+		if entry.RateID == rateID {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
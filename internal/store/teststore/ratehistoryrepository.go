@@ -0,0 +1,70 @@
+package teststore
+
+import (
+	"context"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"sort"
+	"sync"
+	"time"
+)
+
+var _ store.RateHistoryRepository = (*RateHistoryRepository)(nil)
+
+type RateHistoryRepository struct {
+	mu     sync.RWMutex
+	store  *Store
+	points map[string][]*model.RatePoint
+}
+
+func (r *RateHistoryRepository) Append(ctx context.Context, rate *model.Rate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := rate.FirstCurrency + "-" + rate.SecondCurrency
+	r.points[key] = append(r.points[key], &model.RatePoint{
+		Value:  rate.Value,
+		Time:   rate.LastUpdateTime,
+		Source: rate.Source,
+	})
+
+	return nil
+}
+
+func (r *RateHistoryRepository) FindRange(ctx context.Context, firstCurrency, secondCurrency string, start, end time.Time, interval model.HistoryInterval) ([]*model.RatePoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	points := make([]*model.RatePoint, 0)
+	for _, point := range r.points[firstCurrency+"-"+secondCurrency] {
+		if !point.Time.Before(start) && !point.Time.After(end) {
+			points = append(points, point)
+		}
+	}
+
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+
+	return model.Downsample(points, interval), nil
+}
+
+func (r *RateHistoryRepository) FindAsOf(ctx context.Context, firstCurrency, secondCurrency string, t time.Time) (*model.RatePoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found *model.RatePoint
+	for _, point := range r.points[firstCurrency+"-"+secondCurrency] {
+		if point.Time.After(t) {
+			continue
+		}
+
+		if found == nil || point.Time.After(found.Time) {
+			found = point
+		}
+	}
+
+	if found == nil {
+		return nil, store.ErrRowNotFound
+	}
+
+	return found, nil
+}
@@ -1,29 +1,46 @@
 package teststore
 
 import (
+	"context"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"sort"
+	"sync"
+	"time"
 )
 
 var _ store.RateRepository = (*RateRepository)(nil)
 
 type RateRepository struct {
-	store *Store
-	rates map[int]*model.Rate
+	mu     sync.RWMutex
+	store  *Store
+	rates  map[int]*model.Rate
+	lastID int
 }
 
-func (r *RateRepository) Create(rate *model.Rate) error {
+func (r *RateRepository) Create(ctx context.Context, rate *model.Rate) error {
 	if err := rate.Validate(); err != nil {
 		return err
 	}
 
-	rate.ID = len(r.rates) + 1
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.findByCurrencies(rate.FirstCurrency, rate.SecondCurrency) != nil {
+		return store.ErrDuplicate
+	}
+
+	r.lastID++
+	rate.ID = r.lastID
 	r.rates[rate.ID] = rate
 
-	return nil
+	return r.store.RateHistory().Append(ctx, rate)
 }
 
-func (r *RateRepository) Find(id int) (*model.Rate, error) {
+func (r *RateRepository) Find(ctx context.Context, id int) (*model.Rate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	rate, ok := r.rates[id]
 	if !ok {
 		return nil, store.ErrRowNotFound
@@ -32,34 +49,158 @@ func (r *RateRepository) Find(id int) (*model.Rate, error) {
 	return rate, nil
 }
 
-func (r *RateRepository) FindByCurrencies(firstCurrency, secondCurrency string) (*model.Rate, error) {
+func (r *RateRepository) FindByCurrencies(ctx context.Context, firstCurrency, secondCurrency string) (*model.Rate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rate := r.findByCurrencies(firstCurrency, secondCurrency)
+	if rate == nil {
+		return nil, store.ErrRowNotFound
+	}
+
+	return rate, nil
+}
+
+func (r *RateRepository) findByCurrencies(firstCurrency, secondCurrency string) *model.Rate {
 	for _, rate := range r.rates {
 		if rate.FirstCurrency == firstCurrency && rate.SecondCurrency == secondCurrency {
-			return rate, nil
+			return rate
 		}
 	}
 
-	return nil, store.ErrRowNotFound
+	return nil
 }
 
-func (r *RateRepository) FindAll() ([]*model.Rate, error) {
+func (r *RateRepository) FindAll(ctx context.Context) ([]*model.Rate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	var rates []*model.Rate
 
-	for id, rate := range r.rates {
-		rate.ID = id
+	for _, rate := range r.rates {
 		rates = append(rates, rate)
 	}
 
 	return rates, nil
 }
 
-func (r *RateRepository) Update(rate *model.Rate) error {
-	_, err := r.Find(rate.ID)
-	if err != nil {
+func (r *RateRepository) Update(ctx context.Context, rate *model.Rate) error {
+	if err := rate.Validate(); err != nil {
 		return err
 	}
 
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rates[rate.ID]; !ok {
+		return store.ErrRowNotFound
+	}
+
+	if found := r.findByCurrencies(rate.FirstCurrency, rate.SecondCurrency); found != nil && found.ID != rate.ID {
+		return store.ErrDuplicate
+	}
+
+	r.rates[rate.ID] = rate
+
+	return r.store.RateHistory().Append(ctx, rate)
+}
+
+func (r *RateRepository) UpdateWithAudit(ctx context.Context, rate *model.Rate, entry *model.RateAuditEntry) error {
+	if err := r.Update(ctx, rate); err != nil {
+		return err
+	}
+
+	return r.store.RateAudit().Create(ctx, entry)
+}
+
+func (r *RateRepository) Upsert(ctx context.Context, rate *model.Rate) error {
+	if err := rate.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if found := r.findByCurrencies(rate.FirstCurrency, rate.SecondCurrency); found != nil {
+		rate.ID = found.ID
+	} else {
+		r.lastID++
+		rate.ID = r.lastID
+	}
 	r.rates[rate.ID] = rate
 
+	return r.store.RateHistory().Append(ctx, rate)
+}
+
+func (r *RateRepository) UpdateMany(ctx context.Context, rates []*model.Rate) error {
+	for _, rate := range rates {
+		if err := rate.Validate(); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rate := range rates {
+		if found := r.findByCurrencies(rate.FirstCurrency, rate.SecondCurrency); found != nil && found.ID != rate.ID {
+			return store.ErrDuplicate
+		}
+	}
+
+	now := time.Now()
+	for _, rate := range rates {
+		// the rate pinned after it was read is skipped just like the deleted one
+		if current, ok := r.rates[rate.ID]; !ok || current.IsPinned(now) {
+			continue
+		}
+
+		r.rates[rate.ID] = rate
+		if err := r.store.RateHistory().Append(ctx, rate); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+func (r *RateRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rates[id]; !ok {
+		return store.ErrRowNotFound
+	}
+
+	delete(r.rates, id)
+
+	return nil
+}
+
+func (r *RateRepository) List(ctx context.Context, filter *model.RateFilter) ([]*model.Rate, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*model.Rate
+	for _, rate := range r.rates {
+		if filter.Match(rate) {
+			matched = append(matched, rate)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+
+	start := filter.Offset
+	if start > len(matched) {
+		start = len(matched)
+	}
+
+	end := len(matched)
+	if filter.Limit > 0 && start+filter.Limit < end {
+		end = start + filter.Limit
+	}
+
+	rates := make([]*model.Rate, 0, end-start)
+
+	return append(rates, matched[start:end]...), len(matched), nil
+}
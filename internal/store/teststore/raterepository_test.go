@@ -1,6 +1,8 @@
 package teststore_test
 
 import (
+	"context"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
@@ -30,7 +32,7 @@ func TestRateRepository_Create(t *testing.T) {
 				return &model.Rate{
 					FirstCurrency:  "dollar",
 					SecondCurrency: "ruble",
-					Value:          -1,
+					Value:          decimal.NewFromInt(-1),
 					LastUpdateTime: time.Now().Add(time.Second * 10),
 				}
 			},
@@ -40,7 +42,7 @@ func TestRateRepository_Create(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := st.Rate().Create(tc.r())
+			err := st.Rate().Create(context.Background(), tc.r())
 			if tc.isValid {
 				assert.NoError(t, err)
 			} else {
@@ -53,9 +55,9 @@ func TestRateRepository_Create(t *testing.T) {
 func TestRateRepository_Find(t *testing.T) {
 	st := teststore.New()
 	r1 := model.TestRate(t)
-	_ = st.Rate().Create(r1)
+	_ = st.Rate().Create(context.Background(), r1)
 
-	r2, err := st.Rate().Find(r1.ID)
+	r2, err := st.Rate().Find(context.Background(), r1.ID)
 	assert.NoError(t, err)
 	assert.NotNil(t, r2)
 }
@@ -63,11 +65,11 @@ func TestRateRepository_Find(t *testing.T) {
 func TestRateRepository_FindByCurrencies(t *testing.T) {
 	st := teststore.New()
 	r1 := model.TestRate(t)
-	_, err := st.Rate().FindByCurrencies(r1.FirstCurrency, r1.SecondCurrency)
+	_, err := st.Rate().FindByCurrencies(context.Background(), r1.FirstCurrency, r1.SecondCurrency)
 	assert.EqualError(t, err, store.ErrRowNotFound.Error())
 
-	_ = st.Rate().Create(r1)
-	r2, err := st.Rate().FindByCurrencies(r1.FirstCurrency, r1.SecondCurrency)
+	_ = st.Rate().Create(context.Background(), r1)
+	r2, err := st.Rate().FindByCurrencies(context.Background(), r1.FirstCurrency, r1.SecondCurrency)
 	assert.NoError(t, err)
 	assert.NotNil(t, r2)
 }
@@ -79,29 +81,29 @@ func TestRateRepository_FindAll(t *testing.T) {
 		{
 			FirstCurrency:  "USD",
 			SecondCurrency: "RUB",
-			Value:          75.1,
+			Value:          decimal.RequireFromString("75.1"),
 			LastUpdateTime: time.Now(),
 		},
 		{
 			FirstCurrency:  "EUR",
 			SecondCurrency: "USD",
-			Value:          1.1,
+			Value:          decimal.RequireFromString("1.1"),
 			LastUpdateTime: time.Now(),
 		},
 		{
 			FirstCurrency:  "BRL",
 			SecondCurrency: "CAD",
-			Value:          31.51,
+			Value:          decimal.RequireFromString("31.51"),
 			LastUpdateTime: time.Now(),
 		},
 	}
 
 	for _, rate := range rates {
-		err := st.Rate().Create(rate)
+		err := st.Rate().Create(context.Background(), rate)
 		assert.NoError(t, err)
 	}
 
-	rates, err := st.Rate().FindAll()
+	rates, err := st.Rate().FindAll(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, 3, len(rates))
 }
@@ -110,23 +112,23 @@ func TestRateRepository_Update(t *testing.T) {
 	st := teststore.New()
 
 	r := model.TestRate(t)
-	err := st.Rate().Create(r)
+	err := st.Rate().Create(context.Background(), r)
 	assert.NoError(t, err)
 
 	rUpd := &model.Rate{
 		ID:             r.ID,
 		FirstCurrency:  "EUR",
 		SecondCurrency: "USD",
-		Value:          1.1,
+		Value:          decimal.RequireFromString("1.1"),
 		LastUpdateTime: r.LastUpdateTime,
 	}
 
-	err = st.Rate().Update(rUpd)
+	err = st.Rate().Update(context.Background(), rUpd)
 	assert.NoError(t, err)
 
-	rFind, err := st.Rate().Find(r.ID)
+	rFind, err := st.Rate().Find(context.Background(), r.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, rUpd.FirstCurrency, rFind.FirstCurrency)
 	assert.Equal(t, rUpd.SecondCurrency, rFind.SecondCurrency)
-	assert.Equal(t, rUpd.Value, rFind.Value)
+	assert.True(t, rUpd.Value.Equal(rFind.Value))
 }
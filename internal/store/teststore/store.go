@@ -3,12 +3,16 @@ package teststore
 import (
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/model"
 	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"sync"
 )
 
 var _ store.Store = (*Store)(nil)
 
 type Store struct {
-	rateRepository *RateRepository
+	mu                    sync.Mutex
+	rateRepository        *RateRepository
+	rateHistoryRepository *RateHistoryRepository
+	rateAuditRepository   *RateAuditRepository
 }
 
 func New() *Store {
@@ -16,6 +20,9 @@ func New() *Store {
 }
 
 func (s *Store) Rate() store.RateRepository {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if s.rateRepository != nil {
 		return s.rateRepository
 	}
@@ -27,3 +34,35 @@ func (s *Store) Rate() store.RateRepository {
 
 	return s.rateRepository
 }
+
+func (s *Store) RateHistory() store.RateHistoryRepository {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.rateHistoryRepository != nil {
+		return s.rateHistoryRepository
+	}
+
+	s.rateHistoryRepository = &RateHistoryRepository{
+		store:  s,
+		points: make(map[string][]*model.RatePoint),
+	}
+
+	return s.rateHistoryRepository
+}
+
+func (s *Store) RateAudit() store.RateAuditRepository {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.rateAuditRepository != nil {
+		return s.rateAuditRepository
+	}
+
+	s.rateAuditRepository = &RateAuditRepository{
+		store:   s,
+		entries: make([]*model.RateAuditEntry, 0),
+	}
+
+	return s.rateAuditRepository
+}
@@ -0,0 +1,14 @@
+package teststore_test
+
+import (
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/storetest"
+	"github.com/tmrrwnxtsn/currency-conversion-api/internal/store/teststore"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return teststore.New()
+	})
+}
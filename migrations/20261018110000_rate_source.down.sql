@@ -0,0 +1,2 @@
+ALTER TABLE rate
+    DROP COLUMN IF EXISTS source;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE rate
+    ADD COLUMN IF NOT EXISTS source VARCHAR(255) NOT NULL DEFAULT '';
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS rate_history
+(
+    id              BIGSERIAL PRIMARY KEY NOT NULL,
+    first_currency  VARCHAR(5)            NOT NULL,
+    second_currency VARCHAR(5)            NOT NULL,
+    value           REAL                  NOT NULL,
+    source          VARCHAR(255)          NOT NULL DEFAULT '',
+    recorded_at     TIMESTAMP             NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS rate_history_currencies_recorded_at_idx
+    ON rate_history (first_currency, second_currency, recorded_at);
+
+INSERT INTO rate_history (first_currency, second_currency, value, source, recorded_at)
+SELECT first_currency, second_currency, value, source, last_update_time
+FROM rate
+WHERE NOT EXISTS(SELECT 1 FROM rate_history);
\ No newline at end of file
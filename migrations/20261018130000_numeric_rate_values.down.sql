@@ -0,0 +1,5 @@
+ALTER TABLE rate_history
+    ALTER COLUMN value TYPE REAL USING value::REAL;
+
+ALTER TABLE rate
+    ALTER COLUMN value TYPE REAL USING value::REAL;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE rate
+    ALTER COLUMN value TYPE NUMERIC USING value::NUMERIC;
+
+ALTER TABLE rate_history
+    ALTER COLUMN value TYPE NUMERIC USING value::NUMERIC;
\ No newline at end of file
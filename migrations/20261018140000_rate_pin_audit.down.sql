@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS rate_audit;
+
+ALTER TABLE rate
+    DROP COLUMN IF EXISTS pinned_until;
\ No newline at end of file
@@ -0,0 +1,18 @@
+ALTER TABLE rate
+    ADD COLUMN IF NOT EXISTS pinned_until TIMESTAMP NULL;
+
+CREATE TABLE IF NOT EXISTS rate_audit
+(
+    id              BIGSERIAL PRIMARY KEY NOT NULL,
+    rate_id         INTEGER               NOT NULL,
+    first_currency  VARCHAR(5)            NOT NULL,
+    second_currency VARCHAR(5)            NOT NULL,
+    value           NUMERIC               NOT NULL,
+    pinned_until    TIMESTAMP             NULL,
+    author          VARCHAR(255)          NOT NULL DEFAULT '',
+    reason          TEXT                  NOT NULL DEFAULT '',
+    created_at      TIMESTAMP             NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS rate_audit_rate_id_idx
+    ON rate_audit (rate_id);
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE rate
+    DROP CONSTRAINT IF EXISTS rate_value_check,
+    DROP CONSTRAINT IF EXISTS rate_currencies_check;
+
+DROP INDEX IF EXISTS rate_currencies_idx;
\ No newline at end of file
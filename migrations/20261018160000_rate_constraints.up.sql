@@ -0,0 +1,14 @@
+-- the duplicates created by the concurrent requests are removed, the latest rate of the currencies is kept
+DELETE
+FROM rate a
+    USING rate b
+WHERE a.first_currency = b.first_currency
+  AND a.second_currency = b.second_currency
+  AND a.id < b.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS rate_currencies_idx
+    ON rate (first_currency, second_currency);
+
+ALTER TABLE rate
+    ADD CONSTRAINT rate_currencies_check CHECK (first_currency ~ '^[A-Z]{3}$' AND second_currency ~ '^[A-Z]{3}$'),
+    ADD CONSTRAINT rate_value_check CHECK (value > 0);
\ No newline at end of file
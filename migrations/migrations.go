@@ -0,0 +1,31 @@
+// Package migrations embeds the SQL migrations into the binary.
+// The ones in the root are applied to Postgres, the ones in the sqlite directory are applied to SQLite.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+var (
+	//go:embed *.sql
+	postgres embed.FS
+
+	//go:embed sqlite/*.sql
+	sqlite embed.FS
+)
+
+// Postgres returns the migrations of the Postgres database.
+func Postgres() fs.FS {
+	return postgres
+}
+
+// SQLite returns the migrations of the SQLite database.
+func SQLite() fs.FS {
+	sub, err := fs.Sub(sqlite, "sqlite")
+	if err != nil {
+		panic(err)
+	}
+
+	return sub
+}
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS rate_audit;
+
+DROP TABLE IF EXISTS rate_history;
+
+DROP TABLE IF EXISTS rate;
\ No newline at end of file
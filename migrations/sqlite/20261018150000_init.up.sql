@@ -0,0 +1,41 @@
+-- the decimal values are stored as text, SQLite would round them to floats otherwise
+
+CREATE TABLE IF NOT EXISTS rate
+(
+    id               INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    first_currency   VARCHAR(5)                        NOT NULL,
+    second_currency  VARCHAR(5)                        NOT NULL,
+    value            TEXT                              NOT NULL,
+    last_update_time TIMESTAMP                         NOT NULL,
+    source           VARCHAR(255)                      NOT NULL DEFAULT '',
+    pinned_until     TIMESTAMP                         NULL
+);
+
+CREATE TABLE IF NOT EXISTS rate_history
+(
+    id              INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    first_currency  VARCHAR(5)                        NOT NULL,
+    second_currency VARCHAR(5)                        NOT NULL,
+    value           TEXT                              NOT NULL,
+    source          VARCHAR(255)                      NOT NULL DEFAULT '',
+    recorded_at     TIMESTAMP                         NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS rate_history_currencies_recorded_at_idx
+    ON rate_history (first_currency, second_currency, recorded_at);
+
+CREATE TABLE IF NOT EXISTS rate_audit
+(
+    id              INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    rate_id         INTEGER                           NOT NULL,
+    first_currency  VARCHAR(5)                        NOT NULL,
+    second_currency VARCHAR(5)                        NOT NULL,
+    value           TEXT                              NOT NULL,
+    pinned_until    TIMESTAMP                         NULL,
+    author          VARCHAR(255)                      NOT NULL DEFAULT '',
+    reason          TEXT                              NOT NULL DEFAULT '',
+    created_at      TIMESTAMP                         NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS rate_audit_rate_id_idx
+    ON rate_audit (rate_id);
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE rate_old
+(
+    id               INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    first_currency   VARCHAR(5)                        NOT NULL,
+    second_currency  VARCHAR(5)                        NOT NULL,
+    value            TEXT                              NOT NULL,
+    last_update_time TIMESTAMP                         NOT NULL,
+    source           VARCHAR(255)                      NOT NULL DEFAULT '',
+    pinned_until     TIMESTAMP                         NULL
+);
+
+INSERT INTO rate_old (id, first_currency, second_currency, value, last_update_time, source, pinned_until)
+SELECT id, first_currency, second_currency, value, last_update_time, source, pinned_until
+FROM rate;
+
+DROP TABLE rate;
+
+ALTER TABLE rate_old
+    RENAME TO rate;
\ No newline at end of file
@@ -0,0 +1,34 @@
+-- the duplicates created by the concurrent requests are removed, the latest rate of the currencies is kept
+DELETE
+FROM rate
+WHERE EXISTS(SELECT 1
+             FROM rate newer
+             WHERE newer.first_currency = rate.first_currency
+               AND newer.second_currency = rate.second_currency
+               AND newer.id > rate.id);
+
+-- SQLite can't add constraints to an existing table, so the table is recreated with them
+CREATE TABLE rate_new
+(
+    id               INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    first_currency   VARCHAR(5)                        NOT NULL,
+    second_currency  VARCHAR(5)                        NOT NULL,
+    value            TEXT                              NOT NULL,
+    last_update_time TIMESTAMP                         NOT NULL,
+    source           VARCHAR(255)                      NOT NULL DEFAULT '',
+    pinned_until     TIMESTAMP                         NULL,
+    CONSTRAINT rate_currencies_check CHECK (first_currency GLOB '[A-Z][A-Z][A-Z]' AND second_currency GLOB '[A-Z][A-Z][A-Z]'),
+    CONSTRAINT rate_value_check CHECK (CAST(value AS REAL) > 0)
+);
+
+INSERT INTO rate_new (id, first_currency, second_currency, value, last_update_time, source, pinned_until)
+SELECT id, first_currency, second_currency, value, last_update_time, source, pinned_until
+FROM rate;
+
+DROP TABLE rate;
+
+ALTER TABLE rate_new
+    RENAME TO rate;
+
+CREATE UNIQUE INDEX IF NOT EXISTS rate_currencies_idx
+    ON rate (first_currency, second_currency);
\ No newline at end of file